@@ -1,29 +1,65 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/JBK2116/phakelinks/internal/analyzer"
+	"github.com/JBK2116/phakelinks/internal/click"
 	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/generator"
+	"github.com/JBK2116/phakelinks/internal/learner"
 	"github.com/JBK2116/phakelinks/internal/link"
+	"github.com/JBK2116/phakelinks/internal/llm"
 	"github.com/JBK2116/phakelinks/internal/middleware"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/internal/validator"
+	"github.com/JBK2116/phakelinks/migrations"
 	"github.com/gorilla/mux"
 )
 
 func main() {
 	logger := configs.NewLogger(configs.Envs.IsDev)
+	if err := checkIPHashSalt(logger); err != nil {
+		panic(err)
+	}
+	if err := click.SetTrustedProxies(configs.Envs.TrustedProxies); err != nil {
+		panic(err)
+	}
 	db, err := configs.NewDBConn()
 	if err != nil {
 		panic(err)
 	}
-	logger.Info("Database successfully connected")
+	logger.Info("Database successfully connected", slog.String("backend", configs.Envs.StoreBackend))
+	if err := migrate(logger, db); err != nil {
+		panic(err)
+	}
+	store, err := link.NewLinkStore(configs.Envs.StoreBackend, db)
+	if err != nil {
+		panic(err)
+	}
+	provider, err := llm.NewProvider(configs.Envs.LLMProvider, configs.Envs.LLMModel, configs.Envs.LLMBaseURL, configs.Envs.OPENAI_KEY)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("LLM provider configured", slog.String("provider", configs.Envs.LLMProvider), slog.String("model", provider.Model()))
+	var linkValidator validator.Validator = validator.NewLocalValidator()
+	if configs.Envs.UseCloudmersive {
+		linkValidator = validator.NewChainValidator(linkValidator, validator.NewCloudmersiveValidator(configs.Envs.CLOUDMERSIVE_KEY))
+	}
+	clicks := click.NewRecorder(logger, db)
+	sweeper := link.NewSweeper(logger, store, time.Duration(configs.Envs.SweepMinutes)*time.Minute, time.Duration(configs.Envs.RetentionDays)*time.Hour*24)
 	errCh := make(chan error, 2)
 
-	mainServer := NewAPIServer(fmt.Sprintf(":%s", configs.Envs.PublicPort), logger, db)
-	redirectServer := NewAPIServer(fmt.Sprintf(":%s", configs.Envs.RedirectPort), logger, db)
+	mainServer := NewAPIServer(fmt.Sprintf(":%s", configs.Envs.PublicPort), logger, db, store, provider, linkValidator, clicks)
+	redirectServer := NewAPIServer(fmt.Sprintf(":%s", configs.Envs.RedirectPort), logger, db, store, provider, linkValidator, clicks)
 	logger.Info("Main Server running", slog.String("host", configs.Envs.PublicHost), slog.String("port", configs.Envs.PublicPort))
 	logger.Info("Redirect Server running", slog.String("host", configs.Envs.RedirectHost), slog.String("port", configs.Envs.RedirectPort))
 	go func() { errCh <- mainServer.Run() }()
@@ -31,23 +67,70 @@ func main() {
 	if err := <-errCh; err != nil && err != http.ErrServerClosed {
 		logger.Error("Server Failed. Shutting Down ...", slog.String("error", err.Error()))
 		close(errCh)
+		sweeper.Close()
+		clicks.Close()
 		os.Exit(1)
 	}
 }
 
+// checkIPHashSalt() refuses to start without an IPHashSalt outside development, since a known salt lets anyone reverse the hashed IPs.
+// Development gets a random salt, so unique clicks are only unique until the next restart.
+func checkIPHashSalt(logger *slog.Logger) error {
+	if configs.Envs.IPHashSalt != "" {
+		return nil
+	}
+	if !configs.Envs.IsDev {
+		return errors.New("IPHashSalt must be set when IsDev is false")
+	}
+	configs.Envs.IPHashSalt = rand.Text()
+	logger.Warn("IPHashSalt is not set, using a random salt until the next restart")
+	return nil
+}
+
+// migrate() applies pending migrations unless AutoMigrate is off, in which case it refuses to start on a schema that is behind.
+// The memory database is created empty on every start so it always migrates.
+func migrate(logger *slog.Logger, db *sql.DB) error {
+	ctx := context.Background()
+	if configs.Envs.AutoMigrate || configs.Envs.StoreBackend == configs.BackendMemory {
+		version, err := migrations.Up(ctx, logger, db, configs.Envs.StoreBackend)
+		if err != nil {
+			return err
+		}
+		logger.Info("Database schema is up to date", slog.Int64("version", version))
+		return nil
+	}
+	current, latest, err := migrations.Versions(ctx, db, configs.Envs.StoreBackend)
+	if err != nil {
+		return err
+	}
+	if current < latest {
+		return fmt.Errorf("database schema is at version %d but %d is required, apply the migrations or enable AutoMigrate", current, latest)
+	}
+	logger.Info("Database schema version", slog.Int64("version", current))
+	return nil
+}
+
 // APIServer represents an server instance for running the application
 type APIServer struct {
-	address string
-	logger  *slog.Logger
-	db      *sql.DB
+	address   string
+	logger    *slog.Logger
+	db        *sql.DB
+	store     link.LinkStore
+	llm       llm.Provider
+	validator validator.Validator
+	clicks    *click.Recorder
 }
 
 // NewAPIServer() returns a new APIServer instance
-func NewAPIServer(address string, logger *slog.Logger, db *sql.DB) *APIServer {
+func NewAPIServer(address string, logger *slog.Logger, db *sql.DB, store link.LinkStore, provider llm.Provider, v validator.Validator, clicks *click.Recorder) *APIServer {
 	return &APIServer{
-		address: address,
-		logger:  logger,
-		db:      db,
+		address:   address,
+		logger:    logger,
+		db:        db,
+		store:     store,
+		llm:       provider,
+		validator: v,
+		clicks:    clicks,
 	}
 }
 
@@ -56,8 +139,18 @@ func (server *APIServer) Run() error {
 	router := mux.NewRouter()
 	wrappedRouter := middleware.StripTrailingSlashMiddleware(router) // router wrapping is needed here to ensure that middleware runs BEFORE matching to the path
 	subrouter := router.PathPrefix("/api/v1/").Subrouter()
-	linkConn := link.NewLinkConn(server.logger, server.db)
+	linkConn := link.NewLinkConn(server.logger, server.db, server.store, server.llm, server.validator, server.clicks)
 	linkConn.RegisterRoutes(subrouter)
+	techniqueConn := technique.NewTechniqueConn(server.logger)
+	techniqueConn.RegisterRoutes(subrouter)
+	analyzerConn := analyzer.NewAnalyzerConn(server.logger)
+	analyzerConn.RegisterRoutes(subrouter)
+	generatorConn := generator.NewGeneratorConn(server.logger)
+	generatorConn.RegisterRoutes(subrouter)
+	learnerConn := learner.NewLearnerConn(server.logger, server.db)
+	learnerConn.RegisterRoutes(subrouter)
+	clickConn := click.NewClickConn(server.logger, server.db, linkConn.ClickAuthorizer())
+	clickConn.RegisterRoutes(subrouter)
 	if !configs.Envs.IsDev {
 		fs := http.FileServer(http.Dir("/home/jovbk/phakelinks/frontend/dist"))
 		router.PathPrefix("/").Handler(fs)
@@ -68,7 +161,7 @@ func (server *APIServer) Run() error {
 func (server *APIServer) RunRedirect() error {
 	router := mux.NewRouter()
 	wrappedRouter := middleware.StripTrailingSlashMiddleware(router)
-	linkConn := link.NewLinkConn(server.logger, server.db)
+	linkConn := link.NewLinkConn(server.logger, server.db, server.store, server.llm, server.validator, server.clicks)
 	linkConn.RegisterRedirectRoutes(router)
 	return http.ListenAndServe(server.address, wrappedRouter)
 }
@@ -14,7 +14,9 @@ import {
 initUI();
 
 // Global Variables
-let exclude = [];
+let techniques = {};
+
+loadTechniques();
 
 // Submit Handler
 document.getElementById('submitBtn').addEventListener('click', async () => {
@@ -32,20 +34,20 @@ document.getElementById('submitBtn').addEventListener('click', async () => {
         const response = await fetch('/api/v1/links', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ link, mode, exclude }),
+            body: JSON.stringify({ link, mode }),
         });
 
         const data = await response.json();
         if (!response.ok) throw new Error(data.message);
 
         if (mode === 'educational') {
-            addToExclude(data.technique);
-            resetExclude();
+            const technique = techniques[data.technique];
             showEducationalResult({
                 originalLink: data.link,
                 fakeLink: data.fake_link,
-                technique: data.technique,
+                technique: technique?.name || data.technique,
                 explanation: data.explanation,
+                detectionTips: technique?.detection_tips || [],
             });
         } else {
             showPrankResult(data.fake_link);
@@ -59,17 +61,15 @@ document.getElementById('submitBtn').addEventListener('click', async () => {
 });
 
 /**
- * This function resets the exclude array if it surpasses 8 members
+ * This function loads the technique catalog from the server, keyed by technique id
  */
-function resetExclude() {
-    if (exclude.length >= 16) {
-        exclude = [];
+async function loadTechniques() {
+    try {
+        const response = await fetch('/api/v1/techniques');
+        if (!response.ok) return;
+        const data = await response.json();
+        techniques = Object.fromEntries(data.map((t) => [t.id, t]));
+    } catch {
+        techniques = {};
     }
 }
-
-/**
- * This function adds a new technique string to the exclude array
- */
-function addToExclude(technique) {
-    exclude.push(technique);
-}
@@ -118,6 +118,11 @@ export function initUI() {
             <span class="card-label">EXPLANATION</span>
             <p class="card-value explanation-text" id="explanationText">—</p>
           </div>
+
+          <div class="edu-card full-width explanation-card" id="detectionTipsCard">
+            <span class="card-label">HOW TO SPOT IT</span>
+            <ul class="card-value explanation-text" id="detectionTips"></ul>
+          </div>
         </div>
 
         <div class="modal-footer">
@@ -299,7 +304,7 @@ export function hideLoading() {
 
 export function showEducationalResult(data) {
     hideLoading();
-    // data = { originalLink, fakeLink, technique, explanation }
+    // data = { originalLink, fakeLink, technique, explanation, detectionTips }
     document.getElementById('originalLink').textContent =
         data.originalLink || '—';
     document.getElementById('fakeLink').textContent = data.fakeLink || '—';
@@ -308,6 +313,17 @@ export function showEducationalResult(data) {
     document.getElementById('explanationText').textContent =
         data.explanation || '—';
 
+    const tipsList = document.getElementById('detectionTips');
+    tipsList.replaceChildren(
+        ...(data.detectionTips || []).map((tip) => {
+            const item = document.createElement('li');
+            item.textContent = tip;
+            return item;
+        }),
+    );
+    document.getElementById('detectionTipsCard').style.display =
+        data.detectionTips?.length ? '' : 'none';
+
     // Staggered card reveal
     const cards = document.querySelectorAll('.edu-card');
     cards.forEach((c, i) => {
@@ -0,0 +1,641 @@
+// Package analyzer examines a suspicious URL and reports every PhishingTechnique it uses.
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/JBK2116/phakelinks/internal/confusable"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/internal/verifier"
+	"github.com/JBK2116/phakelinks/types"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrInvalidURL is returned when the suspicious URL cannot be parsed at all
+var ErrInvalidURL = errors.New("url could not be parsed")
+
+// portInHost matches a port placed in the middle of a host (e.g. amazon.com:8080.evil.com), which browsers reject but readers accept
+var portInHost = regexp.MustCompile(`^(?:[a-z][a-z0-9+.-]*://)?([^/@\s:]+\.[a-z]{2,}):(\d{1,5})\.`)
+
+// domainLike matches strings that look like a host name
+var domainLike = regexp.MustCompile(`(?i)[a-z0-9\p{L}-]+(?:\.[a-z0-9\p{L}-]+)+`)
+
+// reference represents the brand that a suspicious URL is trying to look like
+type reference struct {
+	brand  string
+	domain string
+	suffix string
+}
+
+// subject represents a parsed suspicious URL
+type subject struct {
+	raw       string
+	url       *url.URL
+	host      string
+	asciiHost string
+	domain    string
+	suffix    string
+	brand     string
+	sub       string
+	ref       *reference
+}
+
+// Analyze() returns every technique detected in the provided URL.
+// The claimed brand (e.g. paypal or paypal.com) is optional and is inferred from the URL when empty.
+func Analyze(rawURL string, claimedBrand string) (types.AnalysisDTO, error) {
+	var analysis types.AnalysisDTO
+	raw := strings.TrimSpace(rawURL)
+	analysis.URL = raw
+	findings := newFindingSet()
+
+	if match := portInHost.FindStringSubmatch(strings.ToLower(raw)); match != nil {
+		findings.add(types.PortAbuse, 0.9, types.EvidenceDTO{
+			Kind:   "port",
+			Detail: fmt.Sprintf("'%s:%s' looks like a host and port, but the text that follows the port is not part of %s", match[1], match[2], match[1]),
+			Value:  match[0],
+		})
+	}
+	s, err := parseSubject(raw)
+	if err != nil {
+		if findings.empty() {
+			return analysis, fmt.Errorf("%w: %s", ErrInvalidURL, err)
+		}
+		analysis.Findings = findings.list()
+		return analysis, nil
+	}
+	if claimedBrand != "" {
+		s.ref = parseReference(claimedBrand)
+		analysis.ClaimedBrand = claimedBrand
+	} else {
+		s.ref = inferReference(s)
+	}
+	analysis.Host = s.host
+	analysis.ASCIIHost = s.asciiHost
+	analysis.RegistrableDomain = s.domain
+	if s.ref != nil {
+		analysis.DisplayedBrand = s.ref.display()
+	}
+
+	detectUserinfo(s, findings)
+	detectPort(s, findings)
+	detectScripts(s, findings)
+	detectPunycode(s, findings)
+	detectHTTPSDeception(s, findings)
+	detectSubdomainAbuse(s, findings)
+	detectPathManipulation(s, findings)
+	detectOpenRedirect(s, findings)
+	detectBrandChanges(s, findings)
+	analysis.Findings = findings.list()
+	return analysis, nil
+}
+
+// parseSubject() parses the suspicious URL into a subject
+func parseSubject(raw string) (subject, error) {
+	s := subject{raw: raw}
+	link := raw
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return s, err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return s, errors.New("missing host")
+	}
+	s.url = u
+	if net.ParseIP(host) != nil {
+		s.host, s.asciiHost, s.domain = host, host, host
+		return s, nil
+	}
+	asciiHost, err := idna.Punycode.ToASCII(host)
+	if err != nil {
+		return s, err
+	}
+	unicodeHost, err := idna.Punycode.ToUnicode(asciiHost)
+	if err != nil {
+		return s, err
+	}
+	s.host = unicodeHost
+	s.asciiHost = asciiHost
+	s.suffix, _ = publicsuffix.PublicSuffix(unicodeHost)
+	domain, err := publicsuffix.EffectiveTLDPlusOne(unicodeHost)
+	if err != nil {
+		s.domain = unicodeHost
+		return s, nil
+	}
+	s.domain = domain
+	s.brand = strings.TrimSuffix(domain, "."+s.suffix)
+	s.sub = strings.TrimSuffix(strings.TrimSuffix(unicodeHost, domain), ".")
+	return s, nil
+}
+
+// parseReference() parses a claimed brand or domain into a reference
+func parseReference(claimed string) *reference {
+	claimed = strings.ToLower(strings.TrimSpace(claimed))
+	if !strings.Contains(claimed, ".") {
+		return &reference{brand: claimed}
+	}
+	if !strings.Contains(claimed, "://") {
+		claimed = "https://" + claimed
+	}
+	u, err := url.Parse(claimed)
+	if err != nil {
+		return nil
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if unicodeHost, err := idna.Punycode.ToUnicode(host); err == nil {
+		host = unicodeHost
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return &reference{brand: host}
+	}
+	suffix, _ := publicsuffix.PublicSuffix(host)
+	return &reference{brand: strings.TrimSuffix(domain, "."+suffix), domain: domain, suffix: suffix}
+}
+
+// inferReference() guesses the brand that the URL displays when no brand was claimed
+func inferReference(s subject) *reference {
+	if s.url.User != nil && strings.Contains(s.url.User.Username(), ".") {
+		if ref := parseReference(s.url.User.Username()); ref != nil && ref.domain != s.domain {
+			return ref
+		}
+	}
+	if embedded := embeddedDomain(s.sub); embedded != "" {
+		return parseReference(embedded)
+	}
+	for _, candidate := range domainLike.FindAllString(s.url.Path, -1) {
+		if ref := parseReference(candidate); ref != nil && ref.domain != s.domain && isKnownBrand(ref.domain) {
+			return ref
+		}
+	}
+	if s.brand == "" {
+		return nil
+	}
+	for _, known := range knownBrands {
+		ref := parseReference(known)
+		if ref.domain == s.domain || (ref.brand == s.brand && isCountryCode(s.suffix)) {
+			return nil
+		}
+		label := verifier.Skeleton(strings.ReplaceAll(s.host, ".", ""))
+		skeleton := verifier.Skeleton(ref.brand)
+		if strings.Contains(label, skeleton) || (len(ref.brand) >= 5 && verifier.Distance(verifier.Skeleton(s.brand), skeleton) <= 1) {
+			return ref
+		}
+	}
+	return nil
+}
+
+// display() returns the most specific name of the reference
+func (ref *reference) display() string {
+	if ref.domain != "" {
+		return ref.domain
+	}
+	return ref.brand
+}
+
+// embeddedDomain() returns the first domain written inside the provided subdomain (e.g. paypal.com in paypal.com.evil)
+func embeddedDomain(sub string) string {
+	labels := strings.Split(sub, ".")
+	for i := 1; i < len(labels); i++ {
+		if labels[i-1] == "" || labels[i] == "www" {
+			continue
+		}
+		if _, icann := publicsuffix.PublicSuffix(labels[i]); icann {
+			return labels[i-1] + "." + labels[i]
+		}
+	}
+	return ""
+}
+
+// detectUserinfo() flags credentials placed in front of the real host
+func detectUserinfo(s subject, findings *findingSet) {
+	if s.url.User == nil {
+		return
+	}
+	username := s.url.User.Username()
+	evidence := types.EvidenceDTO{
+		Kind:   "userinfo",
+		Detail: fmt.Sprintf("everything before the '@' is a username, the browser really connects to %s", s.host),
+		Value:  s.url.User.String(),
+	}
+	confidence := 0.75
+	if strings.Contains(username, ".") {
+		confidence = 0.95
+	}
+	findings.add(types.AtSymbolAbuse, confidence, evidence)
+	if password, ok := s.url.User.Password(); ok && isNumeric(password) {
+		findings.add(types.PortAbuse, 0.9, types.EvidenceDTO{
+			Kind:   "port",
+			Detail: fmt.Sprintf("'%s:%s' looks like a host and port but is really a username and password", username, password),
+			Value:  password,
+		})
+	}
+}
+
+// detectPort() flags non-standard ports on hosts that imitate a brand
+func detectPort(s subject, findings *findingSet) {
+	port := s.url.Port()
+	if port == "" || (s.url.Scheme == "http" && port == "80") || (s.url.Scheme == "https" && port == "443") {
+		return
+	}
+	confidence := 0.5
+	if s.ref != nil && s.domain != s.ref.domain && strings.Contains(verifier.Skeleton(s.host), verifier.Skeleton(s.ref.brand)) {
+		confidence = 0.75
+	}
+	findings.add(types.PortAbuse, confidence, types.EvidenceDTO{
+		Kind:   "port",
+		Detail: fmt.Sprintf("the link uses the non-standard port %s", port),
+		Value:  port,
+	})
+}
+
+// detectScripts() flags labels that mix scripts or are written entirely in a script that imitates Latin
+func detectScripts(s subject, findings *findingSet) {
+	offset := 0
+	for _, label := range strings.Split(s.host, ".") {
+		runes := []rune(label)
+		positions := make([]int, 0)
+		latinConfusable := false
+		for i, c := range runes {
+			if c > unicode.MaxASCII {
+				positions = append(positions, offset+i)
+				if _, ok := confusable.ASCIILookalike(c); ok && confusable.ScriptOf(c) == "Latin" {
+					latinConfusable = true
+				}
+			}
+		}
+		offset += len(runes) + 1
+		if len(positions) == 0 {
+			continue
+		}
+		scripts := confusable.Scripts(label)
+		restriction := confusable.Restriction(label).String()
+		rendered := confusable.Latinize(label)
+		matchesRef := s.ref != nil && verifier.Skeleton(label) == verifier.Skeleton(s.ref.brand)
+		switch {
+		case confusable.WholeScriptConfusable(label):
+			confidence := 0.9
+			if matchesRef {
+				confidence = 0.98
+			}
+			findings.add(types.IDNHomograph, confidence, types.EvidenceDTO{
+				Kind:        "whole-script-confusable",
+				Detail:      fmt.Sprintf("'%s' is written entirely in %s but renders like the Latin '%s'", label, scripts[0], rendered),
+				Value:       label,
+				Positions:   positions,
+				Scripts:     scripts,
+				Restriction: restriction,
+			})
+		case confusable.MixedScript(label) || latinConfusable:
+			confidence := 0.85
+			if matchesRef {
+				confidence = 0.95
+			}
+			mixed := types.EvidenceDTO{
+				Kind:        "mixed-script",
+				Detail:      fmt.Sprintf("'%s' mixes characters from %s, it renders like '%s'", label, strings.Join(scripts, " and "), rendered),
+				Value:       label,
+				Positions:   positions,
+				Scripts:     scripts,
+				Restriction: restriction,
+			}
+			// a label that is mostly rewritten in another script is a homograph with a few Latin letters left over
+			if len(positions)*2 >= len(runes) && isASCII(rendered) {
+				findings.add(types.IDNHomograph, confidence, mixed)
+				findings.add(types.HomoGlyphs, 0.7, mixed)
+				continue
+			}
+			findings.add(types.HomoGlyphs, confidence, mixed)
+		}
+	}
+}
+
+// detectPunycode() flags `xn--` labels that are visible in the link
+func detectPunycode(s subject, findings *findingSet) {
+	if !strings.Contains(strings.ToLower(s.raw), "xn--") {
+		return
+	}
+	for _, label := range strings.Split(s.asciiHost, ".") {
+		if !strings.HasPrefix(label, "xn--") {
+			continue
+		}
+		decoded, err := idna.Punycode.ToUnicode(label)
+		if err != nil {
+			continue
+		}
+		// the encoded form is what the reader sees, so it outranks the homograph it hides
+		confidence := 0.6
+		rendered := confusable.Latinize(decoded)
+		if isASCII(rendered) && rendered != decoded {
+			confidence = 0.99
+		}
+		findings.add(types.Punycode, confidence, types.EvidenceDTO{
+			Kind:        "punycode",
+			Detail:      fmt.Sprintf("'%s' decodes to '%s', which renders like '%s'", label, decoded, rendered),
+			Value:       label,
+			Scripts:     confusable.Scripts(decoded),
+			Restriction: confusable.Restriction(decoded).String(),
+		})
+	}
+}
+
+// detectHTTPSDeception() flags the words http or https inside the host
+func detectHTTPSDeception(s subject, findings *findingSet) {
+	i := strings.Index(s.host, "http")
+	if i == -1 {
+		return
+	}
+	confidence := 0.85
+	evidence := []types.EvidenceDTO{{
+		Kind:      "host",
+		Detail:    "the host contains the word http, which only imitates a secure scheme",
+		Value:     s.host,
+		Positions: []int{len([]rune(s.host[:i]))},
+	}}
+	if s.url.Scheme == "http" {
+		confidence = 0.95
+		evidence = append(evidence, types.EvidenceDTO{Kind: "scheme", Detail: "the link itself uses the insecure http scheme", Value: s.url.Scheme})
+	}
+	findings.add(types.HTTPSDeception, confidence, evidence...)
+}
+
+// detectSubdomainAbuse() flags brands or domains that only appear in the subdomain
+func detectSubdomainAbuse(s subject, findings *findingSet) {
+	if s.sub == "" {
+		return
+	}
+	embedded := embeddedDomain(s.sub)
+	confidence := 0.0
+	if embedded != "" {
+		confidence = 0.8
+	}
+	if s.ref != nil && s.ref.domain != s.domain && strings.Contains(verifier.Skeleton(s.sub), verifier.Skeleton(s.ref.brand)) {
+		confidence = 0.9
+	}
+	if confidence == 0 {
+		return
+	}
+	findings.add(types.SubDomainAbuse, confidence, types.EvidenceDTO{
+		Kind:   "registrable-domain",
+		Detail: fmt.Sprintf("the page is hosted on %s, '%s' is only a subdomain", s.domain, s.sub),
+		Value:  s.domain,
+	})
+}
+
+// detectPathManipulation() flags other domains written inside the path
+func detectPathManipulation(s subject, findings *findingSet) {
+	for _, candidate := range domainLike.FindAllString(s.url.Path, -1) {
+		ref := parseReference(candidate)
+		if ref == nil || ref.domain == "" || ref.domain == s.domain {
+			continue
+		}
+		if _, icann := publicsuffix.PublicSuffix(ref.domain); !icann {
+			continue
+		}
+		confidence := 0.5
+		if s.ref != nil && ref.brand == s.ref.brand {
+			confidence = 0.9
+		}
+		findings.add(types.PathManipulation, confidence, types.EvidenceDTO{
+			Kind:      "path",
+			Detail:    fmt.Sprintf("'%s' only appears in the path, the page is hosted on %s", candidate, s.domain),
+			Value:     candidate,
+			Positions: []int{strings.Index(s.url.Path, candidate)},
+		})
+	}
+}
+
+// detectOpenRedirect() flags query parameters that forward to a different domain
+func detectOpenRedirect(s subject, findings *findingSet) {
+	names := make([]string, 0)
+	query := s.url.Query()
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range query[name] {
+			if !strings.Contains(value, ".") {
+				continue
+			}
+			target := parseReference(value)
+			if target == nil || target.domain == "" || target.domain == s.domain {
+				continue
+			}
+			if _, icann := publicsuffix.PublicSuffix(target.domain); !icann {
+				continue
+			}
+			confidence := 0.6
+			if _, ok := redirectParams[strings.ToLower(name)]; ok {
+				confidence = 0.85
+			}
+			findings.add(types.OpenRedirect, confidence, types.EvidenceDTO{
+				Kind:   "redirect-parameter",
+				Detail: fmt.Sprintf("the '%s' parameter forwards visitors from %s to %s", name, s.domain, target.domain),
+				Value:  fmt.Sprintf("%s=%s", name, value),
+			})
+		}
+	}
+}
+
+// detectBrandChanges() compares the registrable domain with the referenced brand
+func detectBrandChanges(s subject, findings *findingSet) {
+	if s.ref == nil || s.brand == "" || s.domain == s.ref.domain {
+		return
+	}
+	ref := s.ref
+	brand := s.brand
+	registrable := types.EvidenceDTO{
+		Kind:   "registrable-domain",
+		Detail: fmt.Sprintf("the registrable domain is %s, not %s", s.domain, ref.display()),
+		Value:  s.domain,
+	}
+	if brand == ref.brand {
+		if ref.suffix != "" && s.suffix != ref.suffix {
+			findings.add(types.TLDSwap, 0.9, registrable, types.EvidenceDTO{
+				Kind:   "public-suffix",
+				Detail: fmt.Sprintf("the ending .%s replaces .%s", s.suffix, ref.suffix),
+				Value:  s.suffix,
+			})
+		}
+		return
+	}
+	if isASCII(brand) && verifier.Skeleton(brand) == verifier.Skeleton(ref.brand) {
+		if len(brand) == len(ref.brand) {
+			findings.add(types.CharacterSub, 0.9, registrable, types.EvidenceDTO{
+				Kind:      "substitution",
+				Detail:    fmt.Sprintf("'%s' swaps letters of '%s' for similar looking characters", brand, ref.brand),
+				Value:     brand,
+				Positions: diffPositions(brand, ref.brand),
+			})
+		} else {
+			findings.add(types.LookAlikeDomain, 0.85, registrable, types.EvidenceDTO{
+				Kind:   "lookalike",
+				Detail: fmt.Sprintf("'%s' reads like '%s' at a glance", brand, ref.brand),
+				Value:  brand,
+			})
+		}
+		return
+	}
+	if isASCII(brand) && squashRepeats(brand) == squashRepeats(ref.brand) {
+		findings.add(types.LookAlikeDomain, 0.8, registrable, types.EvidenceDTO{
+			Kind:   "lookalike",
+			Detail: fmt.Sprintf("'%s' repeats or drops a letter of '%s'", brand, ref.brand),
+			Value:  brand,
+		})
+		return
+	}
+	if strings.Contains(brand, "-") && strings.ReplaceAll(brand, "-", "") == ref.brand {
+		findings.add(types.HyphenInsertion, 0.9, registrable, types.EvidenceDTO{
+			Kind:      "hyphen",
+			Detail:    fmt.Sprintf("'%s' splits '%s' with a hyphen", brand, ref.brand),
+			Value:     brand,
+			Positions: []int{strings.Index(brand, "-")},
+		})
+		return
+	}
+	if strings.Contains(brand, ref.brand) {
+		findings.add(types.ComboSquatting, 0.85, registrable, types.EvidenceDTO{
+			Kind:   "combo",
+			Detail: fmt.Sprintf("'%s' attaches '%s' to the brand '%s'", brand, strings.Trim(strings.Replace(brand, ref.brand, "", 1), "-"), ref.brand),
+			Value:  brand,
+		})
+		return
+	}
+	if brand == "www"+ref.brand || (strings.Contains(strings.ReplaceAll(s.host, ".", ""), ref.brand) && !strings.Contains(s.host, ref.brand)) {
+		findings.add(types.DotManipulation, 0.85, registrable, types.EvidenceDTO{
+			Kind:   "dots",
+			Detail: fmt.Sprintf("the dots in %s were moved so '%s' is no longer the registrable domain", s.host, ref.brand),
+			Value:  s.host,
+		})
+		return
+	}
+	if isASCII(brand) {
+		if d := verifier.Distance(brand, ref.brand); d >= 1 && d <= 2 {
+			confidence := 0.7
+			if d == 1 {
+				confidence = 0.8
+			}
+			findings.add(types.TypoSquatting, confidence, registrable, types.EvidenceDTO{
+				Kind:      "typo",
+				Detail:    fmt.Sprintf("'%s' is %d keystroke(s) away from '%s'", brand, d, ref.brand),
+				Value:     brand,
+				Positions: diffPositions(brand, ref.brand),
+			})
+		}
+	}
+}
+
+// isKnownBrand() checks if the provided registrable domain belongs to a frequently imitated brand
+func isKnownBrand(domain string) bool {
+	for _, known := range knownBrands {
+		if known == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// isCountryCode() checks if the provided public suffix ends in a country code (e.g. co.uk or de), which brands commonly register
+func isCountryCode(suffix string) bool {
+	labels := strings.Split(suffix, ".")
+	return len(labels[len(labels)-1]) == 2
+}
+
+// diffPositions() returns every index where the two strings differ
+func diffPositions(a string, b string) []int {
+	positions := make([]int, 0)
+	for i := 0; i < len(a); i++ {
+		if i >= len(b) || a[i] != b[i] {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// squashRepeats() collapses runs of the same character into a single character
+func squashRepeats(s string) string {
+	var builder strings.Builder
+	var last rune
+	for i, c := range s {
+		if i == 0 || c != last {
+			builder.WriteRune(c)
+		}
+		last = c
+	}
+	return builder.String()
+}
+
+// isASCII() checks if the provided string only holds ASCII characters
+func isASCII(s string) bool {
+	for _, c := range s {
+		if c > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumeric() checks if the provided string only holds ASCII digits
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// findingSet collects findings and merges repeated detections of the same technique
+type findingSet struct {
+	findings map[types.PhishingTechnique]*types.FindingDTO
+}
+
+// newFindingSet() returns an empty findingSet
+func newFindingSet() *findingSet {
+	return &findingSet{findings: make(map[types.PhishingTechnique]*types.FindingDTO)}
+}
+
+// add() records a finding, keeping the highest confidence and all evidence when the technique was already found
+func (set *findingSet) add(id types.PhishingTechnique, confidence float64, evidence ...types.EvidenceDTO) {
+	if existing, ok := set.findings[id]; ok {
+		existing.Confidence = max(existing.Confidence, confidence)
+		existing.Evidence = append(existing.Evidence, evidence...)
+		return
+	}
+	finding := &types.FindingDTO{Technique: id, Confidence: confidence, Evidence: evidence}
+	if entry, ok := technique.Lookup(string(id)); ok {
+		finding.Name = entry.Name
+		finding.Description = entry.Description
+		finding.DetectionTips = entry.DetectionTips
+	}
+	set.findings[id] = finding
+}
+
+// empty() checks if no findings were recorded
+func (set *findingSet) empty() bool {
+	return len(set.findings) == 0
+}
+
+// list() returns every finding ordered by confidence, highest first
+func (set *findingSet) list() []types.FindingDTO {
+	list := make([]types.FindingDTO, 0, len(set.findings))
+	for _, id := range technique.IDs() {
+		if finding, ok := set.findings[id]; ok {
+			list = append(list, *finding)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Confidence > list[j].Confidence })
+	return list
+}
@@ -0,0 +1,55 @@
+package analyzer
+
+// knownBrands holds the registrable domains of brands that are frequently imitated in phishing campaigns.
+// They are used to infer the displayed brand when the caller does not provide one.
+var knownBrands = []string{
+	"adobe.com",
+	"amazon.com",
+	"apple.com",
+	"bankofamerica.com",
+	"binance.com",
+	"chase.com",
+	"coinbase.com",
+	"dhl.com",
+	"docusign.com",
+	"dropbox.com",
+	"facebook.com",
+	"fedex.com",
+	"github.com",
+	"google.com",
+	"icloud.com",
+	"instagram.com",
+	"linkedin.com",
+	"microsoft.com",
+	"netflix.com",
+	"office.com",
+	"outlook.com",
+	"paypal.com",
+	"spotify.com",
+	"steampowered.com",
+	"ups.com",
+	"usps.com",
+	"wellsfargo.com",
+	"whatsapp.com",
+	"yahoo.com",
+}
+
+// redirectParams holds query parameter names that are commonly used by redirect endpoints
+var redirectParams = map[string]struct{}{
+	"continue":     {},
+	"dest":         {},
+	"destination":  {},
+	"goto":         {},
+	"next":         {},
+	"q":            {},
+	"redir":        {},
+	"redirect":     {},
+	"redirect_to":  {},
+	"redirect_uri": {},
+	"return":       {},
+	"return_to":    {},
+	"returnurl":    {},
+	"target":       {},
+	"u":            {},
+	"url":          {},
+}
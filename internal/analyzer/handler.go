@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/JBK2116/phakelinks/types"
+	"github.com/gorilla/mux"
+)
+
+// AnalyzerConn serves the suspicious URL analyzer.
+type AnalyzerConn struct {
+	logger *slog.Logger
+}
+
+// NewAnalyzerConn() creates a new AnalyzerConn.
+func NewAnalyzerConn(logger *slog.Logger) *AnalyzerConn {
+	return &AnalyzerConn{
+		logger: logger,
+	}
+}
+
+// RegisterRoutes() registers all routes for the AnalyzerConn struct
+func (analyzerConn *AnalyzerConn) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/analyze", analyzerConn.handleAnalyze).Methods("POST")
+}
+
+// handleAnalyze() returns every technique detected in the submitted URL
+func (analyzerConn *AnalyzerConn) handleAnalyze(writer http.ResponseWriter, request *http.Request) {
+	var dto types.AnalyzeDTO
+
+	if err := json.NewDecoder(request.Body).Decode(&dto); err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+		analyzerConn.logger.Error("Error decoding AnalyzeDTO payload", slog.Any("error", err))
+		return
+	}
+	defer request.Body.Close()
+
+	if strings.TrimSpace(dto.URL) == "" {
+		var result types.ValidationResult
+		result.Add("url", "MISSING_URL", "A URL is required.", dto.URL)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(result.ErrorResponse())
+		analyzerConn.logger.Info("Invalid AnalyzeDTO payload", slog.Any("error", result.Errors))
+		return
+	}
+	analysis, err := Analyze(dto.URL, dto.Brand)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "INVALID_URL", Message: "The URL could not be analyzed.", Value: dto.URL, Extra: err.Error()})
+		analyzerConn.logger.Info("Error analyzing URL", slog.Any("error", err.Error()))
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(analysis)
+}
@@ -0,0 +1,275 @@
+// Package click records every visit to a prank link and summarises them into analytics.
+// Clicks are queued in memory and written to the database in batches so the redirect is never delayed.
+package click
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+)
+
+// const here stores all user-agent class enums
+const (
+	UABot     = "bot"
+	UAMobile  = "mobile"
+	UATablet  = "tablet"
+	UADesktop = "desktop"
+	UAUnknown = "unknown"
+)
+
+// const here stores the batching limits of the Recorder
+const (
+	bufferSize    = 1024
+	batchSize     = 100
+	flushInterval = time.Second * 2
+)
+
+// botMarkers holds user-agent fragments used by crawlers, link checkers and command line clients
+var botMarkers = []string{"bot", "crawl", "spider", "slurp", "preview", "fetch", "curl", "wget", "python", "go-http-client", "headless"}
+
+// unfurlMarkers holds user-agent fragments of the bots chat apps and social networks use to build link previews
+var unfurlMarkers = []string{
+	"slackbot", "slack-imgproxy", "discordbot", "facebookexternalhit", "facebot", "twitterbot", "linkedinbot", "whatsapp",
+	"telegrambot", "skypeuripreview", "microsoftpreview", "redditbot", "applebot", "embedly", "iframely", "vkshare",
+	"pinterestbot", "mastodon", "bitlybot", "google-pagerenderer",
+}
+
+// Click represents a single visit to a prank link
+type Click struct {
+	LinkID       int64
+	ClickedAt    time.Time
+	IPHash       string
+	UAClass      string
+	ReferrerHost string
+}
+
+// NewClick() returns the Click for the provided request to the link with the provided id
+func NewClick(request *http.Request, linkID int64) Click {
+	return Click{
+		LinkID:       linkID,
+		ClickedAt:    time.Now().UTC(),
+		IPHash:       HashIP(ClientIP(request)),
+		UAClass:      ClassifyUserAgent(request.UserAgent()),
+		ReferrerHost: ReferrerHost(request.Referer()),
+	}
+}
+
+// trustedProxies holds the reverse proxies whose forwarding headers are believed, set by SetTrustedProxies()
+var trustedProxies []netip.Prefix
+
+// SetTrustedProxies() sets the reverse proxies whose forwarding headers are believed from the provided comma-separated list of IPs and CIDRs
+func SetTrustedProxies(value string) error {
+	prefixes, err := ParseTrustedProxies(value)
+	if err != nil {
+		return err
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+// ParseTrustedProxies() parses the provided comma-separated list of IPs and CIDRs, a bare IP is trusted on its own
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIP() returns the address of the visitor. Forwarding headers are only read when the request comes from a trusted proxy,
+// anyone else could set them to pose as another visitor
+func ClientIP(request *http.Request) string {
+	return clientIP(request, trustedProxies)
+}
+
+// clientIP() returns the right-most `X-Forwarded-For` hop that is not one of the provided trusted proxies,
+// falling back to `X-Real-IP` and then to the address of the connection
+func clientIP(request *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	if !isTrusted(host, trusted) {
+		return host
+	}
+	if forwarded := request.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		// each proxy appends the address it received the request from, so only hops to the right of an untrusted one can be believed
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if !isTrusted(hop, trusted) {
+				return hop
+			}
+			host = hop
+		}
+		return host
+	}
+	if realIP := strings.TrimSpace(request.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return host
+}
+
+// isTrusted() checks if the provided address belongs to one of the provided trusted proxies
+func isTrusted(address string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// HashIP() returns a keyed hash of the provided address so visitors can be counted without storing their IP
+func HashIP(ip string) string {
+	mac := hmac.New(sha256.New, []byte(configs.Envs.IPHashSalt))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// ClassifyUserAgent() returns the user-agent class of the provided user-agent header
+func ClassifyUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return UAUnknown
+	case containsAny(ua, botMarkers) || containsAny(ua, unfurlMarkers):
+		return UABot
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return UATablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return UAMobile
+	case strings.Contains(ua, "mozilla") || strings.Contains(ua, "opera"):
+		return UADesktop
+	default:
+		return UAUnknown
+	}
+}
+
+// IsUnfurlBot() checks if the provided user-agent header belongs to a link preview bot
+func IsUnfurlBot(userAgent string) bool {
+	return containsAny(strings.ToLower(userAgent), unfurlMarkers)
+}
+
+// ReferrerHost() returns the host of the provided referrer header, or "" if there is none
+func ReferrerHost(referrer string) string {
+	parsed, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// containsAny() checks if the provided string contains any of the provided fragments
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Recorder queues clicks and writes them to the database in batches of up to `batchSize`,
+// flushing at least every `flushInterval`
+type Recorder struct {
+	logger *slog.Logger
+	db     *sql.DB
+	clicks chan Click
+	done   chan struct{}
+}
+
+// NewRecorder() creates a new Recorder and starts writing queued clicks in the background.
+func NewRecorder(logger *slog.Logger, db *sql.DB) *Recorder {
+	recorder := &Recorder{
+		logger: logger,
+		db:     db,
+		clicks: make(chan Click, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go recorder.run()
+	return recorder
+}
+
+// Record() queues the click without blocking, the click is dropped if the queue is full
+func (recorder *Recorder) Record(click Click) {
+	select {
+	case recorder.clicks <- click:
+	default:
+		recorder.logger.Warn("Click queue is full, dropping click", slog.Int64("link_id", click.LinkID))
+	}
+}
+
+// Close() writes every queued click and stops the Recorder. Record() must not be called afterwards.
+func (recorder *Recorder) Close() {
+	close(recorder.clicks)
+	<-recorder.done
+}
+
+// run() collects queued clicks into batches until the Recorder is closed
+func (recorder *Recorder) run() {
+	defer close(recorder.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	batch := make([]Click, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := InsertClicks(recorder.db, batch); err != nil {
+			recorder.logger.Error("Error inserting clicks into database", slog.Int("count", len(batch)), slog.Any("error", err.Error()))
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case click, ok := <-recorder.clicks:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
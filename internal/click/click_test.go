@@ -0,0 +1,74 @@
+package click
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		want         string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "spoofed forwarded for from a direct client", remoteAddr: "203.0.113.7:51234", forwardedFor: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "spoofed real ip from a direct client", remoteAddr: "203.0.113.7:51234", realIP: "198.51.100.1", want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.2:443", forwardedFor: []string{"203.0.113.7"}, want: "203.0.113.7"},
+		{name: "spoofed hop before the proxy", remoteAddr: "10.0.0.2:443", forwardedFor: []string{"198.51.100.1, 203.0.113.7"}, want: "203.0.113.7"},
+		{name: "chain of trusted proxies", remoteAddr: "10.0.0.2:443", forwardedFor: []string{"198.51.100.1, 203.0.113.7, 192.0.2.1, 10.1.2.3"}, want: "203.0.113.7"},
+		{name: "repeated headers", remoteAddr: "10.0.0.2:443", forwardedFor: []string{"198.51.100.1", "203.0.113.7"}, want: "203.0.113.7"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.2:443", forwardedFor: []string{"192.0.2.1, 10.1.2.3"}, want: "192.0.2.1"},
+		{name: "real ip from a trusted proxy", remoteAddr: "192.0.2.1:443", realIP: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted proxy without headers", remoteAddr: "10.0.0.2:443", want: "10.0.0.2"},
+		{name: "ipv6 client", remoteAddr: "[2001:db8::1]:443", forwardedFor: []string{"198.51.100.1"}, want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/slug", nil)
+			request.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				request.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := clientIP(request, trusted); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "ip and cidr", value: "10.0.0.0/8,127.0.0.1", want: 2},
+		{name: "whitespace and trailing comma", value: " 10.0.0.0/8 , ::1 ,", want: 2},
+		{name: "invalid ip", value: "10.0.0.256", wantErr: true},
+		{name: "invalid cidr", value: "10.0.0.0/33", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrustedProxies(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedProxies(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ParseTrustedProxies(%q) returned %d prefixes, want %d", tt.value, len(got), tt.want)
+			}
+		})
+	}
+}
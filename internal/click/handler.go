@@ -0,0 +1,74 @@
+package click
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/types"
+	"github.com/gorilla/mux"
+)
+
+// intervals holds the time series intervals accepted by the stats endpoint
+var intervals = map[string]struct{}{"hour": {}, "day": {}, "week": {}, "month": {}}
+
+// LinkAuthorizer returns the id of the link of the request if the request holds its management token.
+// Otherwise it writes the error response and returns false.
+type LinkAuthorizer func(writer http.ResponseWriter, request *http.Request) (int64, bool)
+
+// ClickConn holds the database connection and link authorizer for click analytics queries.
+type ClickConn struct {
+	logger    *slog.Logger
+	db        *sql.DB
+	authorize LinkAuthorizer
+}
+
+// NewClickConn() creates a new ClickConn with the provided database connection and link authorizer.
+func NewClickConn(logger *slog.Logger, db *sql.DB, authorize LinkAuthorizer) *ClickConn {
+	return &ClickConn{
+		logger:    logger,
+		db:        db,
+		authorize: authorize,
+	}
+}
+
+// RegisterRoutes() registers all routes for the ClickConn struct
+func (clickConn *ClickConn) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/links/{slug}/stats", clickConn.handleGetStats).Methods("GET")
+}
+
+// handleGetStats() returns the totals, unique clickers and time series of a prank link to its owner.
+// The optional `interval` query parameter selects the bucket size of the time series and defaults to day.
+func (clickConn *ClickConn) handleGetStats(writer http.ResponseWriter, request *http.Request) {
+	linkID, ok := clickConn.authorize(writer, request)
+	if !ok {
+		return
+	}
+	interval := request.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "day"
+	}
+	if _, ok := intervals[interval]; !ok {
+		var result types.ValidationResult
+		result.Add("interval", "INVALID_INTERVAL", "The interval must be hour, day, week or month.", interval)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(result.ErrorResponse())
+		clickConn.logger.Info("Invalid stats query", slog.Any("error", result.Errors))
+		return
+	}
+	stats, err := GetStats(clickConn.db, configs.Envs.StoreBackend, linkID, interval)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the link stats. Please try again."})
+		clickConn.logger.Info("Error retrieving link stats from database", slog.Any("error", err.Error()))
+		return
+	}
+	stats.Slug = mux.Vars(request)["slug"]
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(stats)
+}
@@ -0,0 +1,113 @@
+package click
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// InsertClicks() Inserts a batch of clicks into the database with a single statement
+func InsertClicks(db *sql.DB, clicks []Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(clicks))
+	args := make([]any, 0, len(clicks)*5)
+	for i, click := range clicks {
+		n := i * 5
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, click.LinkID, click.ClickedAt, click.IPHash, click.UAClass, click.ReferrerHost)
+	}
+	insertStmt := `INSERT INTO clicks (link_id, clicked_at, ip_hash, ua_class, referrer_host) VALUES ` + strings.Join(values, ", ")
+	_, err := db.Exec(insertStmt, args...)
+	return err
+}
+
+// bucketLayout is the layout every bucket expression formats the start of its interval with
+const bucketLayout = "2006-01-02 15:04:05"
+
+// bucketExpr() returns the SQL expression of the start of the interval a click falls in on the provided storage backend.
+// The interval must be hour, day, week or month, weeks start on Monday like `date_trunc` does.
+func bucketExpr(backend string, interval string) string {
+	if backend == configs.BackendPostgres {
+		return fmt.Sprintf(`to_char(date_trunc('%s', clicked_at), 'YYYY-MM-DD HH24:MI:SS')`, interval)
+	}
+	switch interval {
+	case "hour":
+		return `strftime('%Y-%m-%d %H:00:00', clicked_at)`
+	case "week":
+		return `strftime('%Y-%m-%d 00:00:00', clicked_at, '-6 days', 'weekday 1')`
+	case "month":
+		return `strftime('%Y-%m-01 00:00:00', clicked_at)`
+	default:
+		return `strftime('%Y-%m-%d 00:00:00', clicked_at)`
+	}
+}
+
+// GetStats() Retrieves the click analytics of the link with the provided id from the database of the provided storage backend.
+// The interval must be hour, day, week or month, it is validated by the caller.
+func GetStats(db *sql.DB, backend string, linkID int64, interval string) (types.LinkStatsDTO, error) {
+	stats := types.LinkStatsDTO{
+		Interval:   interval,
+		Series:     make([]types.ClickBucketDTO, 0),
+		UserAgents: make(map[string]int),
+		Referrers:  make(map[string]int),
+	}
+	totalStmt := `SELECT COUNT(*), COUNT(DISTINCT ip_hash) FROM clicks WHERE link_id = ($1)`
+	if err := db.QueryRow(totalStmt, linkID).Scan(&stats.Total, &stats.Unique); err != nil {
+		return stats, err
+	}
+
+	seriesStmt := fmt.Sprintf(`SELECT %s AS bucket, COUNT(*), COUNT(DISTINCT ip_hash) FROM clicks
+		WHERE link_id = ($1) GROUP BY bucket ORDER BY bucket`, bucketExpr(backend, interval))
+	rows, err := db.Query(seriesStmt, linkID)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bucket types.ClickBucketDTO
+		var start string
+		if err := rows.Scan(&start, &bucket.Total, &bucket.Unique); err != nil {
+			return stats, err
+		}
+		if bucket.Start, err = time.Parse(bucketLayout, start); err != nil {
+			return stats, err
+		}
+		stats.Series = append(stats.Series, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	if err := countBy(db, linkID, "ua_class", stats.UserAgents); err != nil {
+		return stats, err
+	}
+	if err := countBy(db, linkID, "referrer_host", stats.Referrers); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// countBy() Retrieves the number of clicks per value of the provided column from the database
+func countBy(db *sql.DB, linkID int64, column string, counts map[string]int) error {
+	countStmt := fmt.Sprintf(`SELECT %s, COUNT(*) FROM clicks WHERE link_id = ($1) AND %s <> '' GROUP BY %s`, column, column, column)
+	rows, err := db.Query(countStmt, linkID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			return err
+		}
+		counts[value] = count
+	}
+	return rows.Err()
+}
@@ -1,14 +1,51 @@
 package configs
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-// getNewDBConnection() returns a new database connection to the backend
+// const here stores all storage backend enums
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// memoryConn keeps the in-memory database alive, SQLite drops it once its last connection closes
+var memoryConn *sql.Conn
+
+// getNewDBConnection() returns a new database connection to the configured storage backend.
+// The memory backend keeps links in memory and uses an in-memory SQLite database for everything else.
 func NewDBConn() (*sql.DB, error) {
+	switch Envs.StoreBackend {
+	case BackendPostgres:
+		return newPostgresConn()
+	case BackendSQLite:
+		dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite", url.PathEscape(Envs.SQLitePath))
+		return newSQLiteConn(dsn)
+	case BackendMemory:
+		// foreign keys stay off because the links referenced by clicks are not stored in this database
+		db, err := newSQLiteConn("file:phakelinks?mode=memory&cache=shared&_time_format=sqlite")
+		if err != nil {
+			return nil, err
+		}
+		if memoryConn, err = db.Conn(context.Background()); err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q, expected %s, %s or %s", Envs.StoreBackend, BackendPostgres, BackendSQLite, BackendMemory)
+	}
+}
+
+// newPostgresConn() returns a new connection to the configured Postgres database
+func newPostgresConn() (*sql.DB, error) {
 	var err error
 	connStr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
 		Envs.DBUser,
@@ -26,3 +63,16 @@ func NewDBConn() (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+// newSQLiteConn() returns a new connection to the SQLite database of the provided DSN
+func newSQLiteConn(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -17,11 +17,28 @@ type Config struct {
 	RedirectPort     string
 	OPENAI_KEY       string
 	CLOUDMERSIVE_KEY string
+	UseCloudmersive  bool
+	GeneratorMode    string
+	LLMProvider      string
+	LLMModel         string
+	LLMBaseURL       string
+	StoreBackend     string
+	SQLitePath       string
+	AutoMigrate      bool
 	DBHost           string
 	DBPort           int64
 	DBUser           string
 	DBPassword       string
 	DBName           string
+	IPHashSalt       string
+	TrustedProxies   string
+	SweepMinutes     int64
+	RetentionDays    int64
+	CardTitle        string
+	CardDescription  string
+	CardImage        string
+	QRLevel          string
+	QRSize           int64
 }
 
 // Envs represents the access point for using all configuration variables
@@ -40,11 +57,28 @@ func initConfig() Config {
 		RedirectHost:     getEnv("RedirectHost", "RedirectHost"),
 		OPENAI_KEY:       getEnv("OPENAI_KEY", "OPENAI_KEY"),
 		CLOUDMERSIVE_KEY: getEnv("CLOUDMERSIVE_KEY", "CLOUDMERSIVE_KEY"),
+		UseCloudmersive:  getEnvBool("UseCloudmersive", false),
+		GeneratorMode:    getEnv("GeneratorMode", "llm"),
+		LLMProvider:      getEnv("LLMProvider", "openai"),
+		LLMModel:         getEnv("LLMModel", "gpt-4o"),
+		LLMBaseURL:       getEnv("LLMBaseURL", "http://localhost:11434/v1"),
+		StoreBackend:     getEnv("StoreBackend", "postgres"),
+		SQLitePath:       getEnv("SQLitePath", "phakelinks.db"),
+		AutoMigrate:      getEnvBool("AutoMigrate", true),
 		DBHost:           getEnv("DBHost", "DBHost"),
 		DBPort:           getEnvAsint("DBPort", -1),
 		DBUser:           getEnv("DBUser", "DBUser"),
 		DBPassword:       getEnv("DBPassword", "DBPassword"),
 		DBName:           getEnv("DBName", "DBName"),
+		IPHashSalt:       getEnv("IPHashSalt", ""),
+		TrustedProxies:   getEnv("TrustedProxies", ""),
+		SweepMinutes:     getEnvAsint("SweepMinutes", 10),
+		RetentionDays:    getEnvAsint("RetentionDays", 30),
+		CardTitle:        getEnv("CardTitle", "You've been sent a link"),
+		CardDescription:  getEnv("CardDescription", "Tap to open the link that was shared with you."),
+		CardImage:        getEnv("CardImage", ""),
+		QRLevel:          getEnv("QRLevel", "M"),
+		QRSize:           getEnvAsint("QRSize", 256),
 	}
 }
 
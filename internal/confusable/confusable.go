@@ -0,0 +1,195 @@
+// Package confusable implements the Unicode security mechanisms of UTS #39 that apply to hostnames:
+// confusable skeletons, script resolution, mixed-script detection and restriction levels.
+// The confusable mappings are read from the embedded data/confusables.txt file, which is the unmodified
+// upstream file of `Version` (https://www.unicode.org/Public/security/<Version>/confusables.txt),
+// and the script properties come from the Unicode tables of the standard library, which are generated from the
+// Scripts.txt of the same version (checked by the tests against `unicode.Version`).
+// Two UTS #39 inputs are not embedded and are approximated instead: Script_Extensions (ScriptExtensions.txt) is not
+// used, so a character shared by several scripts only counts for its Script value, and Identifier_Status
+// (IdentifierStatus.txt) is derived from the recommended scripts by `allowed()`. Restriction levels can therefore
+// differ from the reference for rare characters of recommended scripts that UTS #39 restricts (e.g. U+01C0 LATIN LETTER DENTAL CLICK).
+// Upgrading the data means replacing the file and bumping `Version`, anything that only matters to hostnames
+// (e.g. dropping characters IDNA maps back to ASCII) is derived in code by the callers.
+package confusable
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Version is the Unicode version of the embedded confusables.txt
+const Version = "17.0.0"
+
+//go:embed data/confusables.txt
+var confusablesData string
+
+// prototypes maps every source character of confusables.txt to its prototype
+var prototypes = mustParseConfusables(confusablesData)
+
+// asciiLookalikes maps every non-ASCII source character to the single ASCII letter that shares its skeleton
+var asciiLookalikes = buildASCIILookalikes()
+
+// lookalikes is the inverse of asciiLookalikes, sorted by code point
+var lookalikes = buildLookalikes()
+
+// mustParseConfusables() parses the provided confusables.txt contents and panics on malformed data or a version other than `Version`
+func mustParseConfusables(data string) map[rune]string {
+	if version := fileVersion(data); version != Version {
+		panic(fmt.Sprintf("confusables.txt is version %q, expected %q", version, Version))
+	}
+	table, err := parseConfusables(data)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+// fileVersion() returns the Unicode version from the `# Version:` header of confusables.txt
+func fileVersion(data string) string {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		text := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		if !strings.HasPrefix(text, "#") {
+			break
+		}
+		if version, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(text, "#")), "Version:"); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
+
+// parseConfusables() parses the `source ; target ; type # comment` lines of confusables.txt
+func parseConfusables(data string) (map[rune]string, error) {
+	table := make(map[rune]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(strings.TrimPrefix(text, "\ufeff"))
+		if text == "" {
+			continue
+		}
+		fields := strings.Split(text, ";")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("confusables.txt:%d: expected at least 2 fields", line)
+		}
+		source, err := parseCodePoints(fields[0])
+		if err != nil || len(source) != 1 {
+			return nil, fmt.Errorf("confusables.txt:%d: invalid source %q", line, fields[0])
+		}
+		target, err := parseCodePoints(fields[1])
+		if err != nil || len(target) == 0 {
+			return nil, fmt.Errorf("confusables.txt:%d: invalid target %q", line, fields[1])
+		}
+		table[source[0]] = string(target)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// parseCodePoints() parses a space separated list of hexadecimal code points
+func parseCodePoints(field string) ([]rune, error) {
+	runes := make([]rune, 0)
+	for _, hex := range strings.Fields(field) {
+		value, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || !utf8.ValidRune(rune(value)) {
+			return nil, fmt.Errorf("invalid code point %q", hex)
+		}
+		runes = append(runes, rune(value))
+	}
+	return runes, nil
+}
+
+// buildASCIILookalikes() finds the ASCII letter imitated by every non-ASCII source character
+func buildASCIILookalikes() map[rune]rune {
+	letters := make(map[string]rune)
+	// lowercase letters come last so they win when a capital shares their skeleton (I and l)
+	for c := 'A'; c <= 'z'; c++ {
+		if unicode.IsLetter(c) {
+			letters[Skeleton(string(c))] = c
+		}
+	}
+	table := make(map[rune]rune)
+	for source := range prototypes {
+		if source <= unicode.MaxASCII {
+			continue
+		}
+		if letter, ok := letters[Skeleton(string(source))]; ok {
+			table[source] = letter
+		}
+	}
+	return table
+}
+
+// buildLookalikes() groups the non-ASCII source characters by the ASCII letter they imitate
+func buildLookalikes() map[rune][]rune {
+	table := make(map[rune][]rune)
+	for source, letter := range asciiLookalikes {
+		table[letter] = append(table[letter], source)
+	}
+	for _, sources := range table {
+		sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+	}
+	return table
+}
+
+// Skeleton() returns the UTS #39 skeleton of the provided string.
+// Two strings are confusable when their skeletons are equal.
+func Skeleton(s string) string {
+	var builder strings.Builder
+	for _, c := range norm.NFD.String(s) {
+		if prototype, ok := prototypes[c]; ok {
+			builder.WriteString(prototype)
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	return norm.NFD.String(builder.String())
+}
+
+// Confusable() checks if the two strings render alike
+func Confusable(a string, b string) bool {
+	return Skeleton(a) == Skeleton(b)
+}
+
+// Prototype() returns the prototype of the provided character, if it has one
+func Prototype(c rune) (string, bool) {
+	prototype, ok := prototypes[c]
+	return prototype, ok
+}
+
+// ASCIILookalike() returns the ASCII letter that the provided non-ASCII character imitates
+func ASCIILookalike(c rune) (rune, bool) {
+	letter, ok := asciiLookalikes[c]
+	return letter, ok
+}
+
+// Lookalikes() returns every non-ASCII character that imitates the provided ASCII letter
+func Lookalikes(letter rune) []rune {
+	return append([]rune(nil), lookalikes[letter]...)
+}
+
+// Latinize() replaces every non-ASCII character that imitates an ASCII letter with that letter,
+// which is how a reader sees the string
+func Latinize(s string) string {
+	var builder strings.Builder
+	for _, c := range s {
+		if letter, ok := asciiLookalikes[c]; ok {
+			c = letter
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
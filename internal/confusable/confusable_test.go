@@ -0,0 +1,231 @@
+package confusable
+
+import (
+	"slices"
+	"testing"
+	"unicode"
+)
+
+func TestDataVersion(t *testing.T) {
+	// scripts come from the standard library, so it must describe the same Unicode version as the embedded confusables.txt
+	if unicode.Version != Version {
+		t.Errorf("unicode.Version = %q, confusables.txt is %q", unicode.Version, Version)
+	}
+	if version := fileVersion(confusablesData); version != Version {
+		t.Errorf("fileVersion() = %q, want %q", version, Version)
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "ascii", in: "paypal", want: "paypal"},
+		{name: "cyrillic a", in: "pаypal", want: "paypal"},
+		{name: "whole cyrillic", in: "аррӏе", want: "apple"},
+		{name: "rn reads as m", in: "rnicrosoft", want: "rnicrosoft"},
+		{name: "m", in: "microsoft", want: "rnicrosoft"},
+		{name: "digit one", in: "paypa1", want: "paypal"},
+		{name: "capital I", in: "paypaI", want: "paypal"},
+		{name: "zero", in: "g00gle", want: "gOOgle"},
+		{name: "greek omicron", in: "gοοgle", want: "google"},
+		{name: "decomposed", in: "\u00fc", want: "u\u0308"},
+		{name: "unmapped", in: "日本語", want: "日本語"},
+		{name: "empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Skeleton(tt.in); got != tt.want {
+				t.Errorf("Skeleton(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfusable(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "paypal", b: "pаypal", want: true},
+		{a: "apple", b: "аррӏе", want: true},
+		{a: "microsoft", b: "rnicrosoft", want: true},
+		{a: "paypal", b: "paypa1", want: true},
+		{a: "paypal", b: "paypa", want: false},
+		{a: "google", b: "goog1e", want: true},
+		{a: "amazon", b: "arnazon", want: true},
+		{a: "amazon", b: "amaz0n", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := Confusable(tt.a, tt.b); got != tt.want {
+				t.Errorf("Confusable(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScripts(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "paypal", want: []string{"Latin"}},
+		{in: "pаypal", want: []string{"Cyrillic", "Latin"}},
+		{in: "аррӏе", want: []string{"Cyrillic"}},
+		{in: "pay-pal9", want: []string{"Latin"}},
+		{in: "abcあいう日本", want: []string{"Han", "Hiragana", "Latin"}},
+		{in: "123-", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Scripts(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("Scripts(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRestriction follows the restriction levels of UTS #39 section 5.2
+func TestRestriction(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want RestrictionLevel
+	}{
+		{name: "ascii", in: "paypal", want: ASCIIOnly},
+		{name: "ascii with hyphen and digits", in: "pay-pal9", want: ASCIIOnly},
+		{name: "latin with diacritics", in: "münchen", want: SingleScript},
+		{name: "whole cyrillic", in: "аррӏе", want: SingleScript},
+		{name: "greek", in: "ελληνικά", want: SingleScript},
+		{name: "han", in: "日本語", want: SingleScript},
+		{name: "latin and han", in: "abc日本", want: HighlyRestrictive},
+		{name: "latin and japanese", in: "abcあいう日本", want: HighlyRestrictive},
+		{name: "latin and korean", in: "abc한국", want: HighlyRestrictive},
+		{name: "latin and arabic", in: "abcالعربية", want: ModeratelyRestrictive},
+		{name: "paypal with cyrillic a", in: "pаypal", want: MinimallyRestrictive},
+		{name: "latin and greek", in: "abcΑ", want: MinimallyRestrictive},
+		{name: "latin and cyrillic", in: "gооgle", want: MinimallyRestrictive},
+		{name: "limited use script", in: "ꓑ", want: Unrestricted},
+		{name: "underscore", in: "a_b", want: Unrestricted},
+		{name: "emoji", in: "ab😀", want: Unrestricted},
+		{name: "no-break space", in: "ab\u00a0", want: Unrestricted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Restriction(tt.in); got != tt.want {
+				t.Errorf("Restriction(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name string
+		c    rune
+		want bool
+	}{
+		{name: "ascii letter", c: 'a', want: true},
+		{name: "ascii digit", c: '7', want: true},
+		{name: "hyphen", c: '-', want: true},
+		{name: "underscore", c: '_', want: false},
+		{name: "dot", c: '.', want: false},
+		{name: "latin with diacritic", c: 'ü', want: true},
+		{name: "cyrillic", c: 'а', want: true},
+		{name: "han", c: '日', want: true},
+		{name: "combining acute accent", c: '\u0301', want: true},
+		{name: "limited use script", c: 'ꓑ', want: false},
+		{name: "historic script", c: '𐌰', want: false},
+		{name: "symbol", c: '©', want: false},
+		{name: "emoji", c: '😀', want: false},
+		{name: "no-break space", c: '\u00a0', want: false},
+		{name: "zero width joiner", c: '\u200d', want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowed(tt.c); got != tt.want {
+				t.Errorf("allowed(%U) = %v, want %v", tt.c, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWholeScriptConfusable(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want bool
+	}{
+		{name: "whole cyrillic apple", in: "аррӏе", want: true},
+		{name: "whole cyrillic scope", in: "ѕсоре", want: true},
+		{name: "greek omicron", in: "ο", want: true},
+		{name: "latin", in: "apple", want: false},
+		{name: "mixed scripts", in: "pаypal", want: false},
+		{name: "cyrillic with a letter that has no latin lookalike", in: "яблоко", want: false},
+		{name: "greek word", in: "ελληνικά", want: false},
+		{name: "han", in: "日本語", want: false},
+		{name: "digits only", in: "123", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WholeScriptConfusable(tt.in); got != tt.want {
+				t.Errorf("WholeScriptConfusable(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	labels := Labels("xn--pypal-4ve.com")
+	if len(labels) != 2 {
+		t.Fatalf("Labels() returned %d labels, want 2", len(labels))
+	}
+	first := labels[0]
+	if first.Label != "pаypal" || first.Skeleton != "paypal" || !first.MixedScript || first.Restriction != MinimallyRestrictive || first.WholeScriptConfusable {
+		t.Errorf("first label = %+v", first)
+	}
+	if !slices.Equal(first.Scripts, []string{"Cyrillic", "Latin"}) {
+		t.Errorf("first label scripts = %v, want [Cyrillic Latin]", first.Scripts)
+	}
+	if labels[1].Label != "com" || labels[1].Restriction != ASCIIOnly {
+		t.Errorf("second label = %+v", labels[1])
+	}
+}
+
+func TestParseConfusables(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[rune]string
+		wantErr bool
+	}{
+		{name: "single mapping", data: "0430 ;\t0061 ;\tMA\t# ( а → a ) CYRILLIC SMALL LETTER A → LATIN SMALL LETTER A\n", want: map[rune]string{'а': "a"}},
+		{name: "multiple code point target", data: "33A1 ;\t006D 0032 ;\tMA\n", want: map[rune]string{'㎡': "m2"}},
+		{name: "comments and blank lines", data: "\ufeff# confusables.txt\n\n# Version: 17.0.0\n", want: map[rune]string{}},
+		{name: "missing target", data: "0430\n", wantErr: true},
+		{name: "several sources", data: "0430 0431 ; 0061 ; MA\n", wantErr: true},
+		{name: "invalid code point", data: "ZZZZ ; 0061 ; MA\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfusables(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConfusables() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseConfusables() = %v, want %v", got, tt.want)
+			}
+			for source, target := range tt.want {
+				if got[source] != target {
+					t.Errorf("prototype of %U = %q, want %q", source, got[source], target)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package confusable
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// RestrictionLevel represents the UTS #39 restriction level of a string, from most to least restrictive
+type RestrictionLevel int
+
+// const here stores all RestrictionLevel enums
+const (
+	ASCIIOnly RestrictionLevel = iota
+	SingleScript
+	HighlyRestrictive
+	ModeratelyRestrictive
+	MinimallyRestrictive
+	Unrestricted
+)
+
+// String() returns the UTS #39 name of the restriction level
+func (level RestrictionLevel) String() string {
+	switch level {
+	case ASCIIOnly:
+		return "ascii-only"
+	case SingleScript:
+		return "single-script"
+	case HighlyRestrictive:
+		return "highly-restrictive"
+	case ModeratelyRestrictive:
+		return "moderately-restrictive"
+	case MinimallyRestrictive:
+		return "minimally-restrictive"
+	default:
+		return "unrestricted"
+	}
+}
+
+// MarshalText() encodes the restriction level as its UTS #39 name
+func (level RestrictionLevel) MarshalText() ([]byte, error) {
+	return []byte(level.String()), nil
+}
+
+// const here stores the script names that are shared by every script
+const (
+	scriptCommon    = "Common"
+	scriptInherited = "Inherited"
+	scriptLatin     = "Latin"
+)
+
+// scriptNames holds every Unicode script name in a stable order
+var scriptNames = sortedScriptNames()
+
+// recommendedScripts holds the scripts recommended for identifiers by UAX #31 Table 7
+var recommendedScripts = map[string]struct{}{
+	"Arabic": {}, "Armenian": {}, "Bengali": {}, "Bopomofo": {}, "Cyrillic": {}, "Devanagari": {},
+	"Ethiopic": {}, "Georgian": {}, "Greek": {}, "Gujarati": {}, "Gurmukhi": {}, "Han": {},
+	"Hangul": {}, "Hebrew": {}, "Hiragana": {}, "Kannada": {}, "Katakana": {}, "Khmer": {},
+	"Lao": {}, "Latin": {}, "Malayalam": {}, "Myanmar": {}, "Oriya": {}, "Sinhala": {},
+	"Tamil": {}, "Telugu": {}, "Thaana": {}, "Thai": {}, "Tibetan": {},
+}
+
+// highlyRestrictiveSets holds the script combinations that UTS #39 allows at the highly restrictive level
+var highlyRestrictiveSets = [][]string{
+	{scriptLatin, "Han", "Hiragana", "Katakana"},
+	{scriptLatin, "Han", "Bopomofo"},
+	{scriptLatin, "Han", "Hangul"},
+}
+
+// sortedScriptNames() returns the names of every script in the unicode package, sorted
+func sortedScriptNames() []string {
+	names := make([]string, 0, len(unicode.Scripts))
+	for name := range unicode.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ScriptOf() returns the Unicode script of the provided character
+func ScriptOf(c rune) string {
+	switch {
+	case unicode.Is(unicode.Latin, c):
+		return scriptLatin
+	case unicode.Is(unicode.Common, c):
+		return scriptCommon
+	case unicode.Is(unicode.Inherited, c):
+		return scriptInherited
+	}
+	for _, name := range scriptNames {
+		if unicode.Is(unicode.Scripts[name], c) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// Scripts() returns the sorted scripts used by the provided string, ignoring Common and Inherited characters
+func Scripts(s string) []string {
+	seen := make(map[string]struct{})
+	for _, c := range s {
+		script := ScriptOf(c)
+		if script != scriptCommon && script != scriptInherited {
+			seen[script] = struct{}{}
+		}
+	}
+	scripts := make([]string, 0, len(seen))
+	for script := range seen {
+		scripts = append(scripts, script)
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// MixedScript() checks if the provided string uses letters from more than one script
+func MixedScript(s string) bool {
+	return len(Scripts(s)) > 1
+}
+
+// Restriction() returns the UTS #39 restriction level of the provided string
+func Restriction(s string) RestrictionLevel {
+	ascii := true
+	for _, c := range s {
+		if c > unicode.MaxASCII {
+			ascii = false
+		}
+		if !allowed(c) {
+			return Unrestricted
+		}
+	}
+	if ascii {
+		return ASCIIOnly
+	}
+	scripts := Scripts(s)
+	if len(scripts) <= 1 {
+		return SingleScript
+	}
+	for _, set := range highlyRestrictiveSets {
+		if covers(set, scripts) {
+			return HighlyRestrictive
+		}
+	}
+	others := make([]string, 0, len(scripts))
+	for _, script := range scripts {
+		if script != scriptLatin {
+			others = append(others, script)
+		}
+	}
+	if len(others) == 1 && len(others) < len(scripts) && others[0] != "Cyrillic" && others[0] != "Greek" {
+		if _, ok := recommendedScripts[others[0]]; ok {
+			return ModeratelyRestrictive
+		}
+	}
+	return MinimallyRestrictive
+}
+
+// allowed() approximates the UTS #39 Identifier_Status=Allowed property for hostname labels.
+// ASCII letters, digits and hyphens are allowed, as are the letters, digits and nonspacing marks of the
+// UAX #31 recommended scripts and Inherited marks. Unlike IdentifierStatus.txt it does not restrict the
+// obsolete, technical or uncommon characters that recommended scripts also contain.
+func allowed(c rune) bool {
+	if c == '-' || c <= unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+		return true
+	}
+	if c <= unicode.MaxASCII || !(unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.Is(unicode.Mn, c)) {
+		return false
+	}
+	_, ok := recommendedScripts[ScriptOf(c)]
+	return ok || ScriptOf(c) == scriptInherited
+}
+
+// covers() checks if every script is part of the provided set
+func covers(set []string, scripts []string) bool {
+	for _, script := range scripts {
+		found := false
+		for _, candidate := range set {
+			if candidate == script {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Label represents the UTS #39 properties of a single hostname label
+type Label struct {
+	Label                 string           `json:"label"`
+	Skeleton              string           `json:"skeleton"`
+	Scripts               []string         `json:"scripts"`
+	Restriction           RestrictionLevel `json:"restriction"`
+	MixedScript           bool             `json:"mixed_script"`
+	WholeScriptConfusable bool             `json:"whole_script_confusable"`
+}
+
+// Labels() returns the UTS #39 properties of every label of the provided hostname.
+// Punycode labels are decoded first so the properties describe what the reader sees.
+func Labels(host string) []Label {
+	if decoded, err := idna.Punycode.ToUnicode(host); err == nil {
+		host = decoded
+	}
+	labels := make([]Label, 0)
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		scripts := Scripts(label)
+		labels = append(labels, Label{
+			Label:                 label,
+			Skeleton:              Skeleton(label),
+			Scripts:               scripts,
+			Restriction:           Restriction(label),
+			MixedScript:           len(scripts) > 1,
+			WholeScriptConfusable: WholeScriptConfusable(label),
+		})
+	}
+	return labels
+}
+
+// WholeScriptConfusable() checks if the provided string is written in a single non-Latin script
+// and every one of its letters imitates a Latin letter, so the whole string can pass for Latin text
+func WholeScriptConfusable(s string) bool {
+	scripts := Scripts(s)
+	if len(scripts) != 1 || scripts[0] == scriptLatin {
+		return false
+	}
+	for _, c := range s {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		if _, ok := asciiLookalikes[c]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// explanations holds a 4-6 sentence lesson for each technique.
+// Each template receives the original domain, the fake link and the detail of what changed, in that order.
+var explanations = map[types.PhishingTechnique]string{
+	types.CharacterSub:     `This link uses character substitution: %[3]s. Digits such as 0, 1 and 3 look almost identical to the letters o, l and e in most fonts, so %[2]s reads like %[1]s at a glance. Attackers register these domains cheaply and rely on readers skimming instead of reading. To spot it, read the domain one character at a time and watch for digits inside a brand name. When in doubt, type the address yourself or use a saved bookmark.`,
+	types.HomoGlyphs:       `This link uses a homoglyph: %[3]s. The replacement character comes from a different Unicode script but is drawn almost exactly like the original, so %[2]s is visually indistinguishable from %[1]s. Because the domain is technically different, the attacker can register it and obtain a valid certificate for it. To spot it, check whether your browser displays the address in its xn-- form or copy the link into a plain text editor that reveals unusual characters. Password managers also help because they will refuse to autofill credentials on the look-alike domain.`,
+	types.IDNHomograph:     `This link is an internationalized domain name homograph: %[3]s. Internationalized domain names allow letters from alphabets such as Cyrillic, and several of those letters render exactly like Latin ones. The result is that %[2]s looks like %[1]s even though it points to a completely different registration. To spot it, look for the xn-- prefix that many browsers show for mixed or unusual scripts, or inspect the link with a tool that highlights non-Latin characters. Never trust the appearance of a domain alone when you are asked to log in.`,
+	types.DotManipulation:  `This link uses dot manipulation: %[3]s. Every dot in a host name separates a label, and only the last labels decide which organisation actually owns the site. The link %[2]s keeps all the familiar letters of %[1]s, so the eye still reads the brand name. To spot it, find the registrable domain by reading the host right to left from the top-level domain. If the part just before the ending is not the brand you expect, the site belongs to someone else.`,
+	types.HyphenInsertion:  `This link uses hyphen insertion: %[3]s. Hyphens are legal in domain names, and many people assume a hyphenated version is just another official page of the same company. The link %[2]s is a separate registration that has nothing to do with %[1]s. To spot it, compare the domain against the one you normally use and be suspicious of any extra punctuation. Companies rarely move their login pages to hyphenated variants of their main domain.`,
+	types.TLDSwap:          `This link uses a top-level domain swap: %[3]s. Attackers register the same brand name under a different ending because most readers only pay attention to the name itself. The link %[2]s therefore looks familiar even though it is not %[1]s. To spot it, always check the ending of the domain and compare it with the one printed on official correspondence. Bookmarking the real site avoids the problem entirely.`,
+	types.SubDomainAbuse:   `This link uses subdomain abuse: %[3]s. The owner of any domain can create subdomains with any name, including the full name of a trusted company. In %[2]s the familiar text %[1]s appears first, which is where most readers stop reading. To spot it, read the host from right to left and identify the registrable domain just before the ending. The real owner of the page is the domain closest to the top-level domain, not the text at the start.`,
+	types.ComboSquatting:   `This link uses combo-squatting: %[3]s. Words such as login, secure or support sound like something the real company would use, which lowers the reader's guard. The link %[2]s contains the brand of %[1]s but is registered by someone else entirely. To spot it, remember that extra words glued onto a brand with hyphens usually indicate a different domain. Check the exact domain the company uses before entering any information.`,
+	types.TypoSquatting:    `This link uses typosquatting: %[3]s. Attackers register domains that people reach by accident when they mistype a familiar address, and the same domains work well in phishing messages. The link %[2]s differs from %[1]s by a single keystroke, which is easy to overlook. To spot it, read the domain slowly and compare each character with the address you expect. Navigating through bookmarks or search results instead of typing addresses also prevents this trick.`,
+	types.Punycode:         `This link uses punycode: %[3]s. Punycode is the ASCII encoding browsers use for internationalized domain names, and an attacker can register the encoded form of a homograph. When a browser decides to render the name as Unicode, %[2]s is displayed like %[1]s. To spot it, treat any domain starting with xn-- with suspicion when you expected a plain brand name. Many browsers will show the xn-- form in the address bar precisely to warn you.`,
+	types.PathManipulation: `This link uses path manipulation: %[3]s. Everything after the first single slash is only a path on the attacker's server, so placing a trusted host name there costs nothing. The link %[2]s shows %[1]s prominently to make the reader feel safe. To spot it, locate the host between the double slash and the next single slash, which is the only part that decides where you really go. Any familiar name after that point is decoration.`,
+	types.OpenRedirect:     `This link abuses an open redirect: %[3]s. The link %[2]s genuinely starts on %[1]s, so link scanners and careful readers see a trusted domain. The trusted site then forwards the visitor to whatever address was placed in the parameter. To spot it, look for parameters such as url, next or redirect that contain another full address. Check where the browser actually ends up before entering any credentials.`,
+	types.AtSymbolAbuse:    `This link uses at-symbol abuse: %[3]s. URLs allow a username before an @ sign, and browsers quietly ignore it when connecting. The link %[2]s starts with %[1]s, which makes it look like the trusted site. To spot it, find the @ sign in the host part of the address and read only what comes after it. Legitimate websites almost never send links that contain an @ before the domain.`,
+	types.PortAbuse:        `This link uses port abuse: %[3]s. The number after the colon looks like a normal port on the trusted host, so %[2]s appears to point at %[1]s. Because of the @ sign that follows, the browser treats that whole section as login details and connects to a different server. To spot it, look for an @ sign anywhere before the first single slash and read the domain that comes after it. Unexpected ports or credentials in a link are strong signs of a phishing attempt.`,
+	types.HTTPSDeception:   `This link uses https deception: %[3]s. Many people have learned that https means a site is safe, so seeing that word anywhere in a link gives a false sense of security. The link %[2]s mentions both https and %[1]s without actually being either. To spot it, check that the address begins with the https scheme itself and that the registrable domain belongs to the company you expect. A padlock only proves the connection is encrypted, not who you are talking to.`,
+	types.LookAlikeDomain:  `This link uses a look-alike domain: %[3]s. Certain letter combinations such as rn and m or vv and w are almost identical in common fonts, especially on small screens. The link %[2]s is easily read as %[1]s even though it is a different registration. To spot it, zoom in on the address or copy it into a search bar where each character is easier to inspect. Rely on bookmarks or a password manager that only fills credentials on the exact domain.`,
+}
+
+// explain() returns the lesson for the provided technique filled in with the generated variant
+func explain(technique types.PhishingTechnique, t Target, v variant) string {
+	return fmt.Sprintf(explanations[technique], t.Domain(), v.link, v.detail)
+}
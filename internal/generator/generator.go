@@ -0,0 +1,495 @@
+// Package generator builds fake links for every PhishingTechnique without calling an LLM.
+// All output is deterministic, the same technique and link always produce the same fake link.
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JBK2116/phakelinks/internal/confusable"
+	"github.com/JBK2116/phakelinks/types"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	// ErrUnsupportedHost is returned when the provided link has no registrable domain to imitate
+	ErrUnsupportedHost = errors.New("link does not contain a registrable domain")
+	// ErrNoVariant is returned when a technique cannot be applied to the provided domain
+	ErrNoVariant = errors.New("technique cannot be applied to this domain")
+	// ErrUnknownTechnique is returned when the technique is not defined in `types.AllPhishingTechniques`
+	ErrUnknownTechnique = errors.New("unknown phishing technique")
+)
+
+// Target represents the pieces of a legitimate link that the generator rewrites.
+// Internationalized hosts are held in their Unicode form, techniques only edit their ASCII letters
+// and never split a multi-byte letter.
+type Target struct {
+	Scheme string
+	Host   string
+	Sub    string
+	Brand  string
+	Suffix string
+	Rest   string
+}
+
+// Domain() returns the registrable domain of the target (e.g. amazon.co.uk)
+func (t Target) Domain() string {
+	return t.Brand + "." + t.Suffix
+}
+
+// withDomain() returns the target host with the registrable domain replaced by the provided one
+func (t Target) withDomain(domain string) string {
+	if t.Sub == "" {
+		return domain
+	}
+	return t.Sub + "." + domain
+}
+
+// asciiHost() returns the ASCII (punycode) form of the target host, used where a URL cannot hold Unicode such as its userinfo
+func (t Target) asciiHost() string {
+	ascii, err := idna.Punycode.ToASCII(t.Host)
+	if err != nil {
+		return t.Host
+	}
+	return ascii
+}
+
+// path() returns the path and query of the target, defaulting to `fallback` when the target has none
+func (t Target) path(fallback string) string {
+	if t.Rest == "" || t.Rest == "/" {
+		return fallback
+	}
+	return t.Rest
+}
+
+// variant represents a single fake link along with a human readable description of what changed
+type variant struct {
+	link   string
+	detail string
+}
+
+type techniqueFunc func(t Target, r *rand.Rand) (variant, error)
+
+var techniqueFuncs = map[types.PhishingTechnique]techniqueFunc{
+	types.CharacterSub:     characterSubstitution,
+	types.HomoGlyphs:       homoglyph,
+	types.IDNHomograph:     idnHomograph,
+	types.DotManipulation:  dotManipulation,
+	types.HyphenInsertion:  hyphenInsertion,
+	types.TLDSwap:          tldSwap,
+	types.SubDomainAbuse:   subdomainAbuse,
+	types.ComboSquatting:   comboSquatting,
+	types.TypoSquatting:    typoSquatting,
+	types.Punycode:         punycode,
+	types.PathManipulation: pathManipulation,
+	types.OpenRedirect:     openRedirect,
+	types.AtSymbolAbuse:    atSymbolAbuse,
+	types.PortAbuse:        portAbuse,
+	types.HTTPSDeception:   httpsDeception,
+	types.LookAlikeDomain:  lookalikeDomain,
+}
+
+// Generate() returns an `ExplanationDTO` holding a fake link for the provided technique and link
+func Generate(technique types.PhishingTechnique, link string) (types.ExplanationDTO, error) {
+	var dto types.ExplanationDTO
+	fn, ok := techniqueFuncs[technique]
+	if !ok {
+		return dto, fmt.Errorf("%w: %s", ErrUnknownTechnique, technique)
+	}
+	target, err := ParseTarget(link)
+	if err != nil {
+		return dto, err
+	}
+	v, err := fn(target, newRand(string(technique), link))
+	if err != nil {
+		return dto, fmt.Errorf("%s: %w", technique, err)
+	}
+	dto.FakeLink = v.link
+	dto.Technique = string(technique)
+	dto.Explanation = explain(technique, target, v)
+	return dto, nil
+}
+
+// ParseTarget() splits the provided link or domain into the pieces used by the generator
+func ParseTarget(link string) (Target, error) {
+	var target Target
+	link = strings.TrimSpace(link)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return target, err
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" || net.ParseIP(host) != nil {
+		return target, ErrUnsupportedHost
+	}
+	// canonical links hold punycode hosts, the brand is imitated in the form people actually read
+	if unicodeHost, err := idna.Punycode.ToUnicode(host); err == nil {
+		host = unicodeHost
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return target, fmt.Errorf("%w: %s", ErrUnsupportedHost, host)
+	}
+	suffix, _ := publicsuffix.PublicSuffix(host)
+	target.Scheme = parsed.Scheme
+	target.Host = host
+	target.Suffix = suffix
+	target.Brand = strings.TrimSuffix(domain, "."+suffix)
+	target.Sub = strings.TrimSuffix(strings.TrimSuffix(host, domain), ".")
+	target.Rest = parsed.EscapedPath()
+	if parsed.RawQuery != "" {
+		target.Rest += "?" + parsed.RawQuery
+	}
+	return target, nil
+}
+
+// newRand() returns a random source seeded from the provided parts so output is reproducible
+func newRand(parts ...string) *rand.Rand {
+	hash := fnv.New64a()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	seed := hash.Sum64()
+	return rand.New(rand.NewPCG(seed, seed>>1|1))
+}
+
+// pick() returns a random element of the provided slice
+func pick[T any](r *rand.Rand, items []T) T {
+	return items[r.IntN(len(items))]
+}
+
+// attackerDomain() returns a neutral looking domain that an attacker could register
+func attackerDomain(r *rand.Rand) string {
+	return fmt.Sprintf("%s-%s.%s", pick(r, attackerPrefixes), pick(r, attackerSuffixes), pick(r, attackerTLDs))
+}
+
+// buildLink() joins a scheme, host and path into a link string
+func buildLink(scheme string, host string, rest string) string {
+	if scheme == "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, host, rest)
+}
+
+// characterSubstitution() swaps one letter of the brand for a similar looking digit
+func characterSubstitution(t Target, r *rand.Rand) (variant, error) {
+	positions := make([]int, 0)
+	for i := 0; i < len(t.Brand); i++ {
+		if _, ok := asciiSubstitutions[t.Brand[i]]; ok {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return variant{}, ErrNoVariant
+	}
+	i := pick(r, positions)
+	sub := asciiSubstitutions[t.Brand[i]]
+	brand := t.Brand[:i] + string(sub) + t.Brand[i+1:]
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("the letter '%c' was replaced with the character '%c'", t.Brand[i], sub),
+	}, nil
+}
+
+// homoglyphsOf() returns the UTS #39 look-alikes of the letter that a browser displays unchanged.
+// Fullwidth, mathematical and uppercase forms are dropped because IDNA maps them back to plain letters.
+func homoglyphsOf(letter rune) []rune {
+	candidates := make([]rune, 0)
+	for _, c := range confusable.Lookalikes(letter) {
+		encoded, err := idna.Lookup.ToASCII(string(c))
+		if err != nil {
+			continue
+		}
+		if decoded, err := idna.Lookup.ToUnicode(encoded); err == nil && decoded == string(c) {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// homoglyph() swaps one letter of the brand for an identical looking letter from another script
+func homoglyph(t Target, r *rand.Rand) (variant, error) {
+	brand := []rune(t.Brand)
+	positions := make([]int, 0)
+	for i, c := range brand {
+		if len(homoglyphsOf(c)) > 0 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return variant{}, ErrNoVariant
+	}
+	i := pick(r, positions)
+	original := brand[i]
+	brand[i] = pick(r, homoglyphsOf(original))
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(string(brand)+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("the Latin letter '%c' was replaced with the %s look-alike character U+%04X", original, confusable.ScriptOf(brand[i]), brand[i]),
+	}, nil
+}
+
+// inScript() rewrites every letter of the brand that has a look-alike in the provided script
+func inScript(brand string, script string) (string, int) {
+	runes := []rune(brand)
+	count := 0
+	for i, c := range runes {
+		for _, sub := range homoglyphsOf(c) {
+			if confusable.ScriptOf(sub) == script {
+				runes[i] = sub
+				count++
+				break
+			}
+		}
+	}
+	return string(runes), count
+}
+
+// scriptBrand() rewrites the brand in the script from `homographScripts` that covers the most letters
+func scriptBrand(t Target) (string, string, int) {
+	best, bestScript, bestCount := t.Brand, "", 0
+	for _, script := range homographScripts {
+		if brand, count := inScript(t.Brand, script); count > bestCount {
+			best, bestScript, bestCount = brand, script, count
+		}
+	}
+	return best, bestScript, bestCount
+}
+
+// idnHomograph() rewrites the brand with letters of another script that render identically in browsers
+func idnHomograph(t Target, r *rand.Rand) (variant, error) {
+	brand, script, count := scriptBrand(t)
+	if count == 0 {
+		return variant{}, ErrNoVariant
+	}
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("%d of the %d letters in '%s' were replaced with %s look-alikes", count, len([]rune(t.Brand)), t.Brand, script),
+	}, nil
+}
+
+// punycode() encodes a single script homograph of the brand into its `xn--` ASCII form
+func punycode(t Target, r *rand.Rand) (variant, error) {
+	brand, _, count := scriptBrand(t)
+	if count == 0 {
+		return variant{}, ErrNoVariant
+	}
+	encoded, err := idna.Lookup.ToASCII(brand)
+	if err != nil {
+		return variant{}, err
+	}
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(encoded+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("'%s' is the punycode encoding of '%s', which renders like '%s'", encoded, brand, t.Brand),
+	}, nil
+}
+
+// dotManipulation() inserts a dot into the brand or removes the dot after a subdomain
+func dotManipulation(t Target, r *rand.Rand) (variant, error) {
+	variants := make([]variant, 0)
+	for i := 2; i <= len(t.Brand)-2; i++ {
+		if t.Brand[i-1] == '-' || t.Brand[i] == '-' || !utf8.RuneStart(t.Brand[i]) {
+			continue
+		}
+		brand := t.Brand[:i] + "." + t.Brand[i:]
+		variants = append(variants, variant{
+			link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+			detail: fmt.Sprintf("a dot was inserted into '%s', turning '%s' into a subdomain of %s", t.Brand, t.Brand[:i], t.Brand[i:]+"."+t.Suffix),
+		})
+	}
+	if t.Sub != "" {
+		variants = append(variants, variant{
+			link:   buildLink(t.Scheme, t.Sub+t.Domain(), t.Rest),
+			detail: fmt.Sprintf("the dot between '%s' and '%s' was removed, creating the new domain %s", t.Sub, t.Domain(), t.Sub+t.Domain()),
+		})
+	}
+	if len(variants) == 0 {
+		return variant{}, ErrNoVariant
+	}
+	return pick(r, variants), nil
+}
+
+// hyphenInsertion() splits the brand with a hyphen
+func hyphenInsertion(t Target, r *rand.Rand) (variant, error) {
+	positions := make([]int, 0)
+	for i := 1; i < len(t.Brand); i++ {
+		if t.Brand[i-1] != '-' && t.Brand[i] != '-' && utf8.RuneStart(t.Brand[i]) {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return variant{}, ErrNoVariant
+	}
+	i := pick(r, positions)
+	brand := t.Brand[:i] + "-" + t.Brand[i:]
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("a hyphen was inserted into '%s' to create '%s'", t.Brand, brand),
+	}, nil
+}
+
+// tldSwap() replaces the public suffix with another believable top-level domain
+func tldSwap(t Target, r *rand.Rand) (variant, error) {
+	candidates := make([]string, 0, len(believableTLDs))
+	for _, tld := range believableTLDs {
+		if tld != t.Suffix {
+			candidates = append(candidates, tld)
+		}
+	}
+	tld := pick(r, candidates)
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(t.Brand+"."+tld), t.Rest),
+		detail: fmt.Sprintf("the ending '.%s' was swapped for '.%s'", t.Suffix, tld),
+	}, nil
+}
+
+// subdomainAbuse() places the real domain as a subdomain of an attacker domain
+func subdomainAbuse(t Target, r *rand.Rand) (variant, error) {
+	attacker := attackerDomain(r)
+	return variant{
+		link:   buildLink(t.Scheme, t.Domain()+"."+attacker, t.Rest),
+		detail: fmt.Sprintf("'%s' is only a subdomain, the site is really hosted on %s", t.Domain(), attacker),
+	}, nil
+}
+
+// comboSquatting() attaches a legitimate sounding word to the brand
+func comboSquatting(t Target, r *rand.Rand) (variant, error) {
+	word := pick(r, comboWords)
+	brand := t.Brand + "-" + word
+	if r.IntN(2) == 0 {
+		brand = word + "-" + t.Brand
+	}
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("the word '%s' was attached to the brand, creating the unrelated domain %s", word, brand+"."+t.Suffix),
+	}, nil
+}
+
+// typoSquatting() replaces one character of the brand with a neighbouring keyboard key
+func typoSquatting(t Target, r *rand.Rand) (variant, error) {
+	positions := make([]int, 0)
+	for i := 0; i < len(t.Brand); i++ {
+		if _, ok := keyboardAdjacency[t.Brand[i]]; ok {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return variant{}, ErrNoVariant
+	}
+	i := pick(r, positions)
+	neighbours := keyboardAdjacency[t.Brand[i]]
+	key := neighbours[r.IntN(len(neighbours))]
+	brand := t.Brand[:i] + string(key) + t.Brand[i+1:]
+	return variant{
+		link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+		detail: fmt.Sprintf("the key '%c' was replaced with its keyboard neighbour '%c'", t.Brand[i], key),
+	}, nil
+}
+
+// pathManipulation() embeds the real host inside the path of an attacker domain
+func pathManipulation(t Target, r *rand.Rand) (variant, error) {
+	attacker := attackerDomain(r)
+	return variant{
+		link:   buildLink("https", attacker, "/"+t.Host+t.path("/login")),
+		detail: fmt.Sprintf("'%s' only appears in the path, the site is really hosted on %s", t.Host, attacker),
+	}, nil
+}
+
+// openRedirect() abuses a redirect parameter on the real host to forward to an attacker domain
+func openRedirect(t Target, r *rand.Rand) (variant, error) {
+	attacker := attackerDomain(r)
+	path := pick(r, redirectPaths)
+	param := pick(r, redirectParams)
+	destination := buildLink("https", attacker, t.path("/login"))
+	return variant{
+		link:   buildLink(t.Scheme, t.Host, fmt.Sprintf("%s?%s=%s", path, param, url.QueryEscape(destination))),
+		detail: fmt.Sprintf("the '%s' parameter forwards visitors from %s to %s", param, t.Host, attacker),
+	}, nil
+}
+
+// atSymbolAbuse() places the real host in the userinfo section in front of an attacker domain
+func atSymbolAbuse(t Target, r *rand.Rand) (variant, error) {
+	attacker := attackerDomain(r)
+	return variant{
+		link:   buildLink("https", t.asciiHost()+"@"+attacker, t.path("/")),
+		detail: fmt.Sprintf("everything before the '@' is treated as a username, so the browser really visits %s", attacker),
+	}, nil
+}
+
+// portAbuse() makes the real host look like it has a port while the browser visits an attacker domain
+func portAbuse(t Target, r *rand.Rand) (variant, error) {
+	attacker := attackerDomain(r)
+	port := pick(r, deceptivePorts)
+	return variant{
+		link:   buildLink("https", fmt.Sprintf("%s:%s@%s", t.asciiHost(), port, attacker), t.path("/")),
+		detail: fmt.Sprintf("'%s:%s' looks like a host and port but is really a username and password for %s", t.Host, port, attacker),
+	}, nil
+}
+
+// httpsDeception() puts the word https inside the host so an insecure link appears secure
+func httpsDeception(t Target, r *rand.Rand) (variant, error) {
+	attacker := attackerDomain(r)
+	variants := []variant{
+		{
+			link:   buildLink("http", "https."+t.Domain()+"."+attacker, t.Rest),
+			detail: fmt.Sprintf("'https' is only a subdomain of %s and the link itself uses plain http", attacker),
+		},
+		{
+			link:   buildLink("http", t.withDomain("https-"+t.Brand+"."+t.Suffix), t.Rest),
+			detail: "'https' was added to the domain name itself and the link uses plain http",
+		},
+	}
+	return pick(r, variants), nil
+}
+
+// lookalikeDomain() replaces letters with multi-character sequences that read the same at a glance
+func lookalikeDomain(t Target, r *rand.Rand) (variant, error) {
+	variants := make([]variant, 0)
+	keys := make([]string, 0, len(multiCharLookalikes))
+	for key := range multiCharLookalikes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, from := range keys {
+		to := multiCharLookalikes[from]
+		for i := strings.Index(t.Brand, from); i != -1; {
+			brand := t.Brand[:i] + to + t.Brand[i+len(from):]
+			variants = append(variants, variant{
+				link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+				detail: fmt.Sprintf("'%s' was replaced with '%s', which looks almost identical at a glance", from, to),
+			})
+			next := strings.Index(t.Brand[i+1:], from)
+			if next == -1 {
+				break
+			}
+			i += next + 1
+		}
+	}
+	if len(variants) == 0 {
+		for i := 0; i < len(t.Brand); i++ {
+			if t.Brand[i] == '-' || t.Brand[i] == '.' || t.Brand[i] >= utf8.RuneSelf {
+				continue
+			}
+			brand := t.Brand[:i+1] + t.Brand[i:]
+			variants = append(variants, variant{
+				link:   buildLink(t.Scheme, t.withDomain(brand+"."+t.Suffix), t.Rest),
+				detail: fmt.Sprintf("the letter '%c' was doubled, which the eye easily skips over", t.Brand[i]),
+			})
+		}
+	}
+	if len(variants) == 0 {
+		return variant{}, ErrNoVariant
+	}
+	return pick(r, variants), nil
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/JBK2116/phakelinks/internal/verifier"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+func TestGenerateVerifies(t *testing.T) {
+	tests := []struct {
+		name      string
+		link      string
+		noVariant []types.PhishingTechnique
+	}{
+		{name: "brand with path", link: "https://paypal.com/login"},
+		{name: "bare domain", link: "amazon.co.uk"},
+		{name: "subdomain and multi-label suffix", link: "https://mail.google.co.uk/inbox"},
+		{name: "hyphenated brand", link: "https://my-bank.com"},
+		{name: "internationalized brand", link: "https://münchen.de"},
+		{name: "punycode brand", link: "https://xn--mnchen-3ya.de"},
+		{
+			name:      "single letter brand",
+			link:      "https://x.com",
+			noVariant: []types.PhishingTechnique{types.CharacterSub, types.DotManipulation, types.HyphenInsertion},
+		},
+	}
+	for _, tt := range tests {
+		for _, technique := range types.AllPhishingTechniques {
+			t.Run(tt.name+"/"+string(technique), func(t *testing.T) {
+				dto, err := Generate(technique, tt.link)
+				if slices.Contains(tt.noVariant, technique) {
+					if !errors.Is(err, ErrNoVariant) {
+						t.Fatalf("Generate(%s, %q) error = %v, want %v", technique, tt.link, err, ErrNoVariant)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Generate(%s, %q) returned error %v", technique, tt.link, err)
+				}
+				if dto.Technique != string(technique) {
+					t.Errorf("Generate(%s, %q) technique = %q", technique, tt.link, dto.Technique)
+				}
+				if dto.Explanation == "" {
+					t.Errorf("Generate(%s, %q) returned an empty explanation", technique, tt.link)
+				}
+				if err := verifier.Verify(technique, tt.link, dto.FakeLink); err != nil {
+					t.Errorf("Verify(%s, %q, %q) = %v", technique, tt.link, dto.FakeLink, err)
+				}
+				again, _ := Generate(technique, tt.link)
+				if again.FakeLink != dto.FakeLink {
+					t.Errorf("Generate(%s, %q) is not deterministic: %q then %q", technique, tt.link, dto.FakeLink, again.FakeLink)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		technique types.PhishingTechnique
+		link      string
+		err       error
+	}{
+		{name: "unknown technique", technique: "made-up", link: "https://paypal.com", err: ErrUnknownTechnique},
+		{name: "ip address", technique: types.TypoSquatting, link: "http://192.168.0.1", err: ErrUnsupportedHost},
+		{name: "no public suffix", technique: types.TypoSquatting, link: "https://localhost", err: ErrUnsupportedHost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.technique, tt.link); !errors.Is(err, tt.err) {
+				t.Fatalf("Generate(%s, %q) error = %v, want %v", tt.technique, tt.link, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/JBK2116/phakelinks/types"
+	"github.com/gorilla/mux"
+)
+
+// const here stores the page sizes of the permutations endpoint
+const (
+	defaultPermutationLimit = 100
+	maxPermutationLimit     = 1000
+)
+
+// GeneratorConn serves the domain permutation engine.
+type GeneratorConn struct {
+	logger *slog.Logger
+}
+
+// NewGeneratorConn() creates a new GeneratorConn.
+func NewGeneratorConn(logger *slog.Logger) *GeneratorConn {
+	return &GeneratorConn{
+		logger: logger,
+	}
+}
+
+// RegisterRoutes() registers all routes for the GeneratorConn struct
+func (generatorConn *GeneratorConn) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/permutations", generatorConn.handleGetPermutations).Methods("GET")
+}
+
+// handleGetPermutations() returns a page of every lookalike of the `domain` query parameter.
+// The optional `technique` parameter filters the results, `offset` and `limit` select the page.
+func (generatorConn *GeneratorConn) handleGetPermutations(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	domain := strings.TrimSpace(query.Get("domain"))
+	technique := types.PhishingTechnique(query.Get("technique"))
+
+	var result types.ValidationResult
+	if domain == "" {
+		result.Add("domain", "MISSING_DOMAIN", "A domain is required.", domain)
+	}
+	if technique != "" && !slices.Contains(types.AllPhishingTechniques, technique) {
+		result.Add("technique", "INVALID_TECHNIQUE", "The technique is not a valid phishing technique.", string(technique))
+	}
+	offset, err := queryInt(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		result.Add("offset", "INVALID_OFFSET", "The offset must be a non-negative integer.", query.Get("offset"))
+	}
+	limit, err := queryInt(query.Get("limit"), defaultPermutationLimit)
+	if err != nil || limit < 1 || limit > maxPermutationLimit {
+		result.Add("limit", "INVALID_LIMIT", "The limit must be an integer between 1 and 1000.", query.Get("limit"))
+	}
+	if !result.Valid() {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(result.ErrorResponse())
+		generatorConn.logger.Info("Invalid permutations query", slog.Any("error", result.Errors))
+		return
+	}
+
+	permutations, err := Permutations(domain)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "INVALID_DOMAIN", Message: "The domain does not contain a registrable domain.", Value: domain, Extra: err.Error()})
+		generatorConn.logger.Info("Error permuting domain", slog.Any("error", err.Error()))
+		return
+	}
+	if technique != "" {
+		permutations = slices.DeleteFunc(permutations, func(p types.PermutationDTO) bool { return p.Technique != technique })
+	}
+
+	page := types.PermutationPageDTO{
+		Domain:       permutationDomain(domain),
+		Total:        len(permutations),
+		Offset:       offset,
+		Limit:        limit,
+		Permutations: make([]types.PermutationDTO, 0),
+	}
+	if offset < len(permutations) {
+		end := min(offset+limit, len(permutations))
+		page.Permutations = permutations[offset:end]
+		if end < len(permutations) {
+			page.NextOffset = &end
+		}
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(page)
+}
+
+// queryInt() parses an integer query parameter, returning the fallback when it is empty
+func queryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// permutationDomain() returns the registrable domain that was permuted
+func permutationDomain(domain string) string {
+	target, err := ParseTarget(domain)
+	if err != nil {
+		return domain
+	}
+	return target.Domain()
+}
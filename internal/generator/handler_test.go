@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/JBK2116/phakelinks/types"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleGetPermutations(t *testing.T) {
+	router := mux.NewRouter()
+	NewGeneratorConn(slog.New(slog.NewTextHandler(io.Discard, nil))).RegisterRoutes(router)
+
+	// ab.com has 73 permutations, 14 of them swap the suffix
+	tests := []struct {
+		name           string
+		query          string
+		wantTotal      int
+		wantOffset     int
+		wantLimit      int
+		wantCount      int
+		wantNextOffset int
+		wantTechnique  types.PhishingTechnique
+	}{
+		{name: "default page", query: "domain=ab.com", wantTotal: 73, wantLimit: 100, wantCount: 73},
+		{name: "first page", query: "domain=ab.com&limit=50", wantTotal: 73, wantLimit: 50, wantCount: 50, wantNextOffset: 50},
+		{name: "last page", query: "domain=ab.com&offset=50&limit=50", wantTotal: 73, wantOffset: 50, wantLimit: 50, wantCount: 23},
+		{name: "offset past the end", query: "domain=ab.com&offset=100", wantTotal: 73, wantOffset: 100, wantLimit: 100},
+		{name: "filtered by technique", query: "domain=https://ab.com/login&technique=top-level-domain-swap&limit=10", wantTotal: 14, wantLimit: 10, wantCount: 10, wantNextOffset: 10, wantTechnique: types.TLDSwap},
+		{name: "filtered last page", query: "domain=ab.com&technique=top-level-domain-swap&offset=10&limit=10", wantTotal: 14, wantOffset: 10, wantLimit: 10, wantCount: 4, wantTechnique: types.TLDSwap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/permutations?"+tt.query, nil))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+			}
+			var page types.PermutationPageDTO
+			if err := json.NewDecoder(recorder.Body).Decode(&page); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if page.Domain != "ab.com" || page.Total != tt.wantTotal || page.Offset != tt.wantOffset || page.Limit != tt.wantLimit {
+				t.Errorf("page = {domain %q, total %d, offset %d, limit %d}, want {ab.com, %d, %d, %d}", page.Domain, page.Total, page.Offset, page.Limit, tt.wantTotal, tt.wantOffset, tt.wantLimit)
+			}
+			if len(page.Permutations) != tt.wantCount {
+				t.Errorf("page has %d permutations, want %d", len(page.Permutations), tt.wantCount)
+			}
+			switch {
+			case tt.wantNextOffset == 0 && page.NextOffset != nil:
+				t.Errorf("next_offset = %d, want none", *page.NextOffset)
+			case tt.wantNextOffset != 0 && (page.NextOffset == nil || *page.NextOffset != tt.wantNextOffset):
+				t.Errorf("next_offset = %v, want %d", page.NextOffset, tt.wantNextOffset)
+			}
+			for _, p := range page.Permutations {
+				if tt.wantTechnique != "" && p.Technique != tt.wantTechnique {
+					t.Errorf("%q tagged %q, want only %q", p.Domain, p.Technique, tt.wantTechnique)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetPermutationsPagesInOrder(t *testing.T) {
+	router := mux.NewRouter()
+	NewGeneratorConn(slog.New(slog.NewTextHandler(io.Discard, nil))).RegisterRoutes(router)
+	want, err := Permutations("ab.com")
+	if err != nil {
+		t.Fatalf("Permutations() error = %v", err)
+	}
+
+	// following next_offset must walk every permutation once, in order
+	var got []types.PermutationDTO
+	query := "domain=ab.com&limit=20"
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatal("next_offset never ran out")
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/permutations?"+query, nil))
+		var page types.PermutationPageDTO
+		if err := json.NewDecoder(recorder.Body).Decode(&page); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		got = append(got, page.Permutations...)
+		if page.NextOffset == nil {
+			break
+		}
+		query = "domain=ab.com&limit=20&offset=" + strconv.Itoa(*page.NextOffset)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("paging returned %d permutations, want the %d of Permutations() in order", len(got), len(want))
+	}
+}
+
+func TestHandleGetPermutationsInvalid(t *testing.T) {
+	router := mux.NewRouter()
+	NewGeneratorConn(slog.New(slog.NewTextHandler(io.Discard, nil))).RegisterRoutes(router)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantError  string
+		wantFields []string
+	}{
+		{name: "missing domain", query: "", wantError: "MISSING_DOMAIN", wantFields: []string{"domain"}},
+		{name: "blank domain", query: "domain=%20", wantError: "MISSING_DOMAIN", wantFields: []string{"domain"}},
+		{name: "unknown technique", query: "domain=ab.com&technique=made-up", wantError: "INVALID_TECHNIQUE", wantFields: []string{"technique"}},
+		{name: "negative offset", query: "domain=ab.com&offset=-1", wantError: "INVALID_OFFSET", wantFields: []string{"offset"}},
+		{name: "non-integer offset", query: "domain=ab.com&offset=ten", wantError: "INVALID_OFFSET", wantFields: []string{"offset"}},
+		{name: "zero limit", query: "domain=ab.com&limit=0", wantError: "INVALID_LIMIT", wantFields: []string{"limit"}},
+		{name: "limit above the maximum", query: "domain=ab.com&limit=1001", wantError: "INVALID_LIMIT", wantFields: []string{"limit"}},
+		{name: "non-integer limit", query: "domain=ab.com&limit=abc", wantError: "INVALID_LIMIT", wantFields: []string{"limit"}},
+		{name: "every parameter invalid", query: "technique=made-up&offset=-1&limit=0", wantError: "MISSING_DOMAIN", wantFields: []string{"domain", "technique", "offset", "limit"}},
+		{name: "no registrable domain", query: "domain=co.uk", wantError: "INVALID_DOMAIN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/permutations?"+tt.query, nil))
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body.String())
+			}
+			var response types.ErrorResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if response.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", response.Error, tt.wantError)
+			}
+			var fields []string
+			for _, fieldErr := range response.Errors {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("invalid fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
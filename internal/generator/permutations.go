@@ -0,0 +1,275 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JBK2116/phakelinks/types"
+	"golang.org/x/net/idna"
+)
+
+// permutation represents a single lookalike brand before it is joined with a suffix
+type permutation struct {
+	domain string
+	detail string
+}
+
+type permutationFunc func(t Target) []permutation
+
+// permutationFuncs holds every technique that can be applied to a registrable domain on its own.
+// Techniques that need an attacker controlled host (e.g. subdomain abuse or open redirects) cannot be enumerated,
+// and punycode is covered by the `xn--` form that every homograph reports as its ASCII domain.
+var permutationFuncs = map[types.PhishingTechnique]permutationFunc{
+	types.CharacterSub:    characterSubstitutionPermutations,
+	types.HomoGlyphs:      homoglyphPermutations,
+	types.IDNHomograph:    idnHomographPermutations,
+	types.DotManipulation: dotManipulationPermutations,
+	types.HyphenInsertion: hyphenInsertionPermutations,
+	types.TLDSwap:         tldSwapPermutations,
+	types.ComboSquatting:  comboSquattingPermutations,
+	types.TypoSquatting:   typoSquattingPermutations,
+	types.HTTPSDeception:  httpsDeceptionPermutations,
+	types.LookAlikeDomain: lookalikeDomainPermutations,
+}
+
+// Permutations() returns every lookalike of the registrable domain of the provided link or domain.
+// The output is ordered by `types.AllPhishingTechniques` and each domain is only reported for the first technique that produces it.
+func Permutations(link string) ([]types.PermutationDTO, error) {
+	target, err := ParseTarget(link)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	if ascii, ok := registrable(target.Domain()); ok {
+		seen[ascii] = struct{}{}
+	}
+	permutations := make([]types.PermutationDTO, 0)
+	for _, technique := range types.AllPhishingTechniques {
+		fn, ok := permutationFuncs[technique]
+		if !ok {
+			continue
+		}
+		for _, p := range fn(target) {
+			ascii, ok := registrable(p.domain)
+			if !ok {
+				continue
+			}
+			if _, ok := seen[ascii]; ok {
+				continue
+			}
+			seen[ascii] = struct{}{}
+			permutations = append(permutations, types.PermutationDTO{
+				Domain:    p.domain,
+				ASCII:     ascii,
+				Technique: technique,
+				Detail:    p.detail,
+			})
+		}
+	}
+	return permutations, nil
+}
+
+// registrable() returns the ASCII form of the provided domain if every label of it can be registered
+func registrable(domain string) (string, bool) {
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil || len(ascii) > 253 {
+		return "", false
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", false
+		}
+	}
+	return ascii, true
+}
+
+// characterSubstitutionPermutations() swaps every letter of the brand for its similar looking digit, one at a time
+func characterSubstitutionPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	for i := 0; i < len(t.Brand); i++ {
+		if sub, ok := asciiSubstitutions[t.Brand[i]]; ok {
+			permutations = append(permutations, permutation{
+				domain: t.Brand[:i] + string(sub) + t.Brand[i+1:] + "." + t.Suffix,
+				detail: fmt.Sprintf("the letter '%c' was replaced with the character '%c'", t.Brand[i], sub),
+			})
+		}
+	}
+	return permutations
+}
+
+// homoglyphPermutations() swaps every letter of the brand for each of its look-alikes from another script, one at a time
+func homoglyphPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	brand := []rune(t.Brand)
+	for i, c := range brand {
+		for _, sub := range homoglyphsOf(c) {
+			swapped := append([]rune(nil), brand...)
+			swapped[i] = sub
+			permutations = append(permutations, permutation{
+				domain: string(swapped) + "." + t.Suffix,
+				detail: fmt.Sprintf("the Latin letter '%c' was replaced with the look-alike character U+%04X", c, sub),
+			})
+		}
+	}
+	return permutations
+}
+
+// idnHomographPermutations() rewrites the brand in every script of `homographScripts` that has look-alikes for it
+func idnHomographPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	for _, script := range homographScripts {
+		if brand, count := inScript(t.Brand, script); count > 0 {
+			permutations = append(permutations, permutation{
+				domain: brand + "." + t.Suffix,
+				detail: fmt.Sprintf("%d of the %d letters in '%s' were replaced with %s look-alikes", count, len([]rune(t.Brand)), t.Brand, script),
+			})
+		}
+	}
+	return permutations
+}
+
+// dotManipulationPermutations() inserts a dot at every position of the brand and removes the dot after a www subdomain
+func dotManipulationPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	for i := 1; i < len(t.Brand); i++ {
+		if !utf8.RuneStart(t.Brand[i]) {
+			continue
+		}
+		permutations = append(permutations, permutation{
+			domain: t.Brand[:i] + "." + t.Brand[i:] + "." + t.Suffix,
+			detail: fmt.Sprintf("a dot was inserted into '%s', turning '%s' into a subdomain", t.Brand, t.Brand[:i]),
+		})
+	}
+	subs := []string{"www"}
+	if t.Sub != "" && t.Sub != "www" {
+		subs = append(subs, strings.ReplaceAll(t.Sub, ".", ""))
+	}
+	for _, sub := range subs {
+		permutations = append(permutations, permutation{
+			domain: sub + t.Domain(),
+			detail: fmt.Sprintf("the dot between '%s' and '%s' was removed", sub, t.Domain()),
+		})
+	}
+	return permutations
+}
+
+// hyphenInsertionPermutations() splits the brand with a hyphen at every position
+func hyphenInsertionPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	for i := 1; i < len(t.Brand); i++ {
+		if !utf8.RuneStart(t.Brand[i]) {
+			continue
+		}
+		permutations = append(permutations, permutation{
+			domain: t.Brand[:i] + "-" + t.Brand[i:] + "." + t.Suffix,
+			detail: fmt.Sprintf("a hyphen was inserted into '%s'", t.Brand),
+		})
+	}
+	return permutations
+}
+
+// tldSwapPermutations() pairs the brand with every believable top-level domain
+func tldSwapPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0, len(believableTLDs))
+	for _, tld := range believableTLDs {
+		permutations = append(permutations, permutation{
+			domain: t.Brand + "." + tld,
+			detail: fmt.Sprintf("the ending '.%s' was swapped for '.%s'", t.Suffix, tld),
+		})
+	}
+	return permutations
+}
+
+// comboSquattingPermutations() attaches every combo word to either side of the brand
+func comboSquattingPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0, len(comboWords)*2)
+	for _, word := range comboWords {
+		for _, brand := range []string{t.Brand + "-" + word, word + "-" + t.Brand} {
+			permutations = append(permutations, permutation{
+				domain: brand + "." + t.Suffix,
+				detail: fmt.Sprintf("the word '%s' was attached to the brand", word),
+			})
+		}
+	}
+	return permutations
+}
+
+// typoSquattingPermutations() returns every single keystroke mistake of the brand:
+// pressing a neighbouring key, adding a neighbouring key, skipping a key and swapping two keys
+func typoSquattingPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	for i := 0; i < len(t.Brand); i++ {
+		for _, key := range []byte(keyboardAdjacency[t.Brand[i]]) {
+			permutations = append(permutations,
+				permutation{
+					domain: t.Brand[:i] + string(key) + t.Brand[i+1:] + "." + t.Suffix,
+					detail: fmt.Sprintf("the key '%c' was replaced with its keyboard neighbour '%c'", t.Brand[i], key),
+				},
+				permutation{
+					domain: t.Brand[:i] + string(key) + t.Brand[i:] + "." + t.Suffix,
+					detail: fmt.Sprintf("the keyboard neighbour '%c' was pressed before '%c'", key, t.Brand[i]),
+				},
+			)
+		}
+	}
+	for i := 0; i < len(t.Brand); i++ {
+		if t.Brand[i] >= utf8.RuneSelf {
+			continue
+		}
+		permutations = append(permutations, permutation{
+			domain: t.Brand[:i] + t.Brand[i+1:] + "." + t.Suffix,
+			detail: fmt.Sprintf("the letter '%c' was left out", t.Brand[i]),
+		})
+	}
+	for i := 0; i+1 < len(t.Brand); i++ {
+		if t.Brand[i] == t.Brand[i+1] || t.Brand[i] >= utf8.RuneSelf || t.Brand[i+1] >= utf8.RuneSelf {
+			continue
+		}
+		permutations = append(permutations, permutation{
+			domain: t.Brand[:i] + string(t.Brand[i+1]) + string(t.Brand[i]) + t.Brand[i+2:] + "." + t.Suffix,
+			detail: fmt.Sprintf("the letters '%c' and '%c' were swapped", t.Brand[i], t.Brand[i+1]),
+		})
+	}
+	return permutations
+}
+
+// httpsDeceptionPermutations() puts the word https in front of the brand
+func httpsDeceptionPermutations(t Target) []permutation {
+	return []permutation{
+		{domain: "https-" + t.Brand + "." + t.Suffix, detail: "'https' was added to the domain name itself"},
+		{domain: "https" + t.Brand + "." + t.Suffix, detail: "'https' was added to the domain name itself"},
+	}
+}
+
+// lookalikeDomainPermutations() replaces every multi-character look-alike sequence and doubles every letter of the brand
+func lookalikeDomainPermutations(t Target) []permutation {
+	permutations := make([]permutation, 0)
+	keys := make([]string, 0, len(multiCharLookalikes))
+	for key := range multiCharLookalikes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, from := range keys {
+		to := multiCharLookalikes[from]
+		for i := 0; i+len(from) <= len(t.Brand); i++ {
+			if t.Brand[i:i+len(from)] == from {
+				permutations = append(permutations, permutation{
+					domain: t.Brand[:i] + to + t.Brand[i+len(from):] + "." + t.Suffix,
+					detail: fmt.Sprintf("'%s' was replaced with '%s', which looks almost identical at a glance", from, to),
+				})
+			}
+		}
+	}
+	for i := 0; i < len(t.Brand); i++ {
+		if t.Brand[i] == '-' || t.Brand[i] == '.' || t.Brand[i] >= utf8.RuneSelf {
+			continue
+		}
+		permutations = append(permutations, permutation{
+			domain: t.Brand[:i+1] + t.Brand[i:] + "." + t.Suffix,
+			detail: fmt.Sprintf("the letter '%c' was doubled, which the eye easily skips over", t.Brand[i]),
+		})
+	}
+	return permutations
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/JBK2116/phakelinks/types"
+)
+
+func TestPermutations(t *testing.T) {
+	permutations, err := Permutations("https://www.ab.com/login")
+	if err != nil {
+		t.Fatalf("Permutations() error = %v", err)
+	}
+	// ab.com is short enough to enumerate by hand, it has no idn homograph as no script has look-alikes for both letters
+	wantCounts := map[types.PhishingTechnique]int{
+		types.CharacterSub:    2,
+		types.HomoGlyphs:      7,
+		types.DotManipulation: 2,
+		types.HyphenInsertion: 1,
+		types.TLDSwap:         14,
+		types.ComboSquatting:  24,
+		types.TypoSquatting:   19,
+		types.HTTPSDeception:  2,
+		types.LookAlikeDomain: 2,
+	}
+	counts := make(map[types.PhishingTechnique]int)
+	for _, p := range permutations {
+		counts[p.Technique]++
+	}
+	for _, technique := range types.AllPhishingTechniques {
+		if counts[technique] != wantCounts[technique] {
+			t.Errorf("%d permutations tagged %q, want %d", counts[technique], technique, wantCounts[technique])
+		}
+	}
+
+	tests := []struct {
+		name      string
+		domain    string
+		ascii     string
+		technique types.PhishingTechnique
+	}{
+		{name: "digit for letter", domain: "4b.com", ascii: "4b.com", technique: types.CharacterSub},
+		{name: "greek alpha", domain: "αb.com", ascii: "xn--b-ylb.com", technique: types.HomoGlyphs},
+		{name: "inserted dot", domain: "a.b.com", ascii: "a.b.com", technique: types.DotManipulation},
+		{name: "removed www dot", domain: "wwwab.com", ascii: "wwwab.com", technique: types.DotManipulation},
+		{name: "inserted hyphen", domain: "a-b.com", ascii: "a-b.com", technique: types.HyphenInsertion},
+		{name: "swapped suffix", domain: "ab.net", ascii: "ab.net", technique: types.TLDSwap},
+		{name: "appended keyword", domain: "ab-login.com", ascii: "ab-login.com", technique: types.ComboSquatting},
+		{name: "neighbouring key", domain: "qb.com", ascii: "qb.com", technique: types.TypoSquatting},
+		{name: "transposed letters", domain: "ba.com", ascii: "ba.com", technique: types.TypoSquatting},
+		{name: "https prefix", domain: "https-ab.com", ascii: "https-ab.com", technique: types.HTTPSDeception},
+		{name: "doubled letter", domain: "aab.com", ascii: "aab.com", technique: types.LookAlikeDomain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, p := range permutations {
+				if p.Domain != tt.domain {
+					continue
+				}
+				if p.ASCII != tt.ascii || p.Technique != tt.technique || p.Detail == "" {
+					t.Errorf("permutation %q = %+v, want ascii %q tagged %q", tt.domain, p, tt.ascii, tt.technique)
+				}
+				return
+			}
+			t.Errorf("Permutations() is missing %q", tt.domain)
+		})
+	}
+}
+
+func TestPermutationsDeduplicates(t *testing.T) {
+	target, err := ParseTarget("go.com")
+	if err != nil {
+		t.Fatalf("ParseTarget() error = %v", err)
+	}
+	permutations, err := Permutations("go.com")
+	if err != nil {
+		t.Fatalf("Permutations() error = %v", err)
+	}
+	// first holds the first technique in catalog order that produces each domain
+	first := make(map[string]types.PhishingTechnique)
+	for _, technique := range types.AllPhishingTechniques {
+		fn, ok := permutationFuncs[technique]
+		if !ok {
+			continue
+		}
+		for _, p := range fn(target) {
+			if ascii, ok := registrable(p.domain); ok {
+				if _, ok := first[ascii]; !ok {
+					first[ascii] = technique
+				}
+			}
+		}
+	}
+	delete(first, "go.com")
+	if len(permutations) != len(first) {
+		t.Errorf("Permutations() returned %d domains, want %d", len(permutations), len(first))
+	}
+	seen := make(map[string]bool)
+	for _, p := range permutations {
+		if seen[p.ASCII] {
+			t.Errorf("Permutations() repeats %q", p.ASCII)
+		}
+		seen[p.ASCII] = true
+		if p.ASCII == "go.com" {
+			t.Errorf("Permutations() includes the original domain as %q", p.Technique)
+		}
+		if p.Technique != first[p.ASCII] {
+			t.Errorf("%q tagged %q, want the first technique that produces it %q", p.ASCII, p.Technique, first[p.ASCII])
+		}
+	}
+	// typosquatting also swaps 'o' for its neighbouring key '0', but character substitution comes first in the catalog
+	if first["g0.com"] != types.CharacterSub {
+		t.Errorf("g0.com tagged %q, want %q", first["g0.com"], types.CharacterSub)
+	}
+}
+
+func TestPermutationsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+	}{
+		{name: "empty", link: ""},
+		{name: "no suffix", link: "xyz"},
+		{name: "bare public suffix", link: "co.uk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if permutations, err := Permutations(tt.link); err == nil {
+				t.Errorf("Permutations(%q) = %d permutations, want an error", tt.link, len(permutations))
+			}
+		})
+	}
+}
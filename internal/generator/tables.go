@@ -0,0 +1,161 @@
+package generator
+
+// asciiSubstitutions maps a lowercase letter to a visually similar ASCII character
+var asciiSubstitutions = map[byte]byte{
+	'a': '4',
+	'b': '8',
+	'e': '3',
+	'g': '9',
+	'i': '1',
+	'l': '1',
+	'o': '0',
+	's': '5',
+	't': '7',
+	'z': '2',
+}
+
+// multiCharLookalikes maps a character sequence to a sequence that renders almost identically in most fonts
+var multiCharLookalikes = map[string]string{
+	"m":  "rn",
+	"w":  "vv",
+	"d":  "cl",
+	"rn": "m",
+	"vv": "w",
+	"cl": "d",
+	"nn": "m",
+}
+
+// homographScripts holds the scripts that a whole brand can be rewritten in, the first one wins ties
+var homographScripts = []string{"Cyrillic", "Greek", "Armenian"}
+
+// keyboardAdjacency maps each key on a US QWERTY keyboard to the keys touching it
+var keyboardAdjacency = map[byte]string{
+	'1': "2q",
+	'2': "13qw",
+	'3': "24we",
+	'4': "35er",
+	'5': "46rt",
+	'6': "57ty",
+	'7': "68yu",
+	'8': "79ui",
+	'9': "80io",
+	'0': "9op",
+	'q': "12wa",
+	'w': "23qeas",
+	'e': "34wrsd",
+	'r': "45etdf",
+	't': "56ryfg",
+	'y': "67tugh",
+	'u': "78yihj",
+	'i': "89uojk",
+	'o': "90ipkl",
+	'p': "0ol",
+	'a': "qwsz",
+	's': "weadzx",
+	'd': "erfscx",
+	'f': "rtgdvc",
+	'g': "tyhfbv",
+	'h': "yujgnb",
+	'j': "uikhmn",
+	'k': "ioljm",
+	'l': "opk",
+	'z': "asx",
+	'x': "zsdc",
+	'c': "xdfv",
+	'v': "cfgb",
+	'b': "vghn",
+	'n': "bhjm",
+	'm': "njk",
+}
+
+// believableTLDs holds top-level domains that non-technical users commonly accept as legitimate
+var believableTLDs = []string{
+	"com",
+	"net",
+	"org",
+	"co",
+	"io",
+	"app",
+	"info",
+	"biz",
+	"us",
+	"online",
+	"site",
+	"support",
+	"services",
+	"com.co",
+	"co.uk",
+}
+
+// comboWords holds words that are commonly attached to a brand in combo-squatted domains
+var comboWords = []string{
+	"login",
+	"secure",
+	"account",
+	"support",
+	"verify",
+	"billing",
+	"update",
+	"help",
+	"signin",
+	"security",
+	"service",
+	"online",
+}
+
+// attackerPrefixes and attackerSuffixes are combined into neutral looking attacker controlled domains
+var attackerPrefixes = []string{
+	"secure",
+	"account",
+	"login",
+	"verify",
+	"auth",
+	"user",
+	"id",
+	"customer",
+}
+
+var attackerSuffixes = []string{
+	"portal",
+	"center",
+	"check",
+	"review",
+	"gateway",
+	"access",
+	"services",
+}
+
+var attackerTLDs = []string{
+	"com",
+	"net",
+	"info",
+	"co",
+	"online",
+	"site",
+}
+
+// redirectPaths and redirectParams are combined into believable open redirect endpoints
+var redirectPaths = []string{
+	"/redirect",
+	"/out",
+	"/away",
+	"/login",
+	"/link",
+}
+
+var redirectParams = []string{
+	"url",
+	"next",
+	"redirect",
+	"return_to",
+	"continue",
+	"dest",
+}
+
+// deceptivePorts holds ports that read naturally next to a domain name
+var deceptivePorts = []string{
+	"443",
+	"8443",
+	"8080",
+	"4433",
+}
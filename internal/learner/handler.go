@@ -0,0 +1,59 @@
+package learner
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// LearnerConn holds the database connection for learner session queries.
+type LearnerConn struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// NewLearnerConn() creates a new LearnerConn with the provided database connection.
+func NewLearnerConn(logger *slog.Logger, db *sql.DB) *LearnerConn {
+	return &LearnerConn{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// RegisterRoutes() registers all routes for the LearnerConn struct
+func (learnerConn *LearnerConn) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/learner/progress", learnerConn.handleGetProgress).Methods("GET")
+	router.HandleFunc("/learner/progress", learnerConn.handleResetProgress).Methods("DELETE")
+}
+
+// handleGetProgress() returns the mastery of every technique for the learner session of the request
+func (learnerConn *LearnerConn) handleGetProgress(writer http.ResponseWriter, request *http.Request) {
+	id := SessionID(writer, request)
+	history, err := GetHistory(learnerConn.db, id)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving your progress. Please try again."})
+		learnerConn.logger.Info("Error retrieving learner history from database", slog.Any("error", err.Error()))
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(Progress(history))
+}
+
+// handleResetProgress() forgets every technique the learner session of the request has seen or answered
+func (learnerConn *LearnerConn) handleResetProgress(writer http.ResponseWriter, request *http.Request) {
+	id := SessionID(writer, request)
+	if err := DeleteHistory(learnerConn.db, id); err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while resetting your progress. Please try again."})
+		learnerConn.logger.Info("Error deleting learner history from database", slog.Any("error", err.Error()))
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
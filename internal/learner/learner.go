@@ -0,0 +1,116 @@
+// Package learner tracks anonymous learner sessions and how well each learner does on every PhishingTechnique.
+// A learner is identified by a random id stored in a cookie, no account is required.
+package learner
+
+import (
+	"crypto/rand"
+	mathrand "math/rand/v2"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// CookieName is the name of the cookie that stores the learner id
+const CookieName = "phakelinks_learner"
+
+// cookieMaxAge is how long a learner session survives without a visit
+const cookieMaxAge = time.Hour * 24 * 365
+
+// sessionID matches the ids created by `crypto/rand.Text()`
+var sessionID = regexp.MustCompile(`^[A-Z2-7]{26}$`)
+
+// SessionID() returns the learner id stored in the request cookie, creating a new one if it is missing or malformed.
+// The cookie is refreshed on every call so active learners keep their history.
+func SessionID(writer http.ResponseWriter, request *http.Request) string {
+	id := ""
+	if cookie, err := request.Cookie(CookieName); err == nil && sessionID.MatchString(cookie.Value) {
+		id = cookie.Value
+	} else {
+		id = rand.Text()
+	}
+	http.SetCookie(writer, &http.Cookie{
+		Name:     CookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   !configs.Envs.IsDev,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// Progress() returns the mastery of every technique in the catalog, including the ones the learner has not seen yet
+func Progress(history []types.MasteryDTO) types.ProgressDTO {
+	byTechnique := make(map[types.PhishingTechnique]types.MasteryDTO, len(history))
+	for _, entry := range history {
+		byTechnique[entry.Technique] = entry
+	}
+	progress := types.ProgressDTO{Techniques: make([]types.MasteryDTO, 0, technique.Count())}
+	for _, id := range technique.IDs() {
+		entry, ok := byTechnique[id]
+		if !ok {
+			entry = types.MasteryDTO{Technique: id}
+		}
+		progress.Techniques = append(progress.Techniques, entry)
+	}
+	return progress
+}
+
+// Mastery() returns the share of correct answers, or 0 if the technique was never answered
+func Mastery(attempts int, correct int) float64 {
+	if attempts == 0 {
+		return 0
+	}
+	return float64(correct) / float64(attempts)
+}
+
+// NextTechnique() picks the next technique for a learner from every technique that is not excluded.
+// Each technique is weighted by (1 + missed answers) / (1 + times seen), so unseen techniques and
+// techniques the learner keeps getting wrong come up more often than the ones already mastered.
+// It returns false if every technique is excluded.
+func NextTechnique(history []types.MasteryDTO, excludes []string) (types.PhishingTechnique, bool) {
+	candidates, weights := techniqueWeights(history, excludes)
+	if len(candidates) == 0 {
+		return "", false
+	}
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	target := mathrand.Float64() * total
+	for i, weight := range weights {
+		if target < weight {
+			return candidates[i], true
+		}
+		target -= weight
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// techniqueWeights() returns every technique that is not excluded along with the weight `NextTechnique()` picks it by
+func techniqueWeights(history []types.MasteryDTO, excludes []string) ([]types.PhishingTechnique, []float64) {
+	byTechnique := make(map[types.PhishingTechnique]types.MasteryDTO, len(history))
+	for _, entry := range history {
+		byTechnique[entry.Technique] = entry
+	}
+	excluded := make(map[string]struct{}, len(excludes))
+	for _, v := range excludes {
+		excluded[v] = struct{}{}
+	}
+	candidates := make([]types.PhishingTechnique, 0)
+	weights := make([]float64, 0)
+	for _, id := range technique.IDs() {
+		if _, ok := excluded[string(id)]; ok {
+			continue
+		}
+		entry := byTechnique[id]
+		candidates = append(candidates, id)
+		weights = append(weights, float64(1+entry.Attempts-entry.Correct)/float64(1+entry.Seen))
+	}
+	return candidates, weights
+}
@@ -0,0 +1,139 @@
+package learner
+
+import (
+	"testing"
+
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+func TestNextTechnique(t *testing.T) {
+	all := make([]string, 0, technique.Count())
+	for _, id := range technique.IDs() {
+		all = append(all, string(id))
+	}
+	tests := []struct {
+		name     string
+		history  []types.MasteryDTO
+		excludes []string
+		want     types.PhishingTechnique
+		wantOK   bool
+	}{
+		{name: "every technique excluded", excludes: all, wantOK: false},
+		{name: "one technique left", excludes: all[1:], want: technique.IDs()[0], wantOK: true},
+		{name: "one technique left whatever the history", history: []types.MasteryDTO{{Technique: technique.IDs()[0], Seen: 50, Attempts: 50, Correct: 50}}, excludes: all[1:], want: technique.IDs()[0], wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the pick is random so every case is drawn a few times
+			for range 20 {
+				got, ok := NextTechnique(tt.history, tt.excludes)
+				if ok != tt.wantOK || got != tt.want {
+					t.Fatalf("NextTechnique() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestNextTechniqueNeverPicksExcluded(t *testing.T) {
+	excludes := []string{string(types.HomoGlyphs), string(types.TLDSwap), string(types.AtSymbolAbuse)}
+	for range 500 {
+		got, ok := NextTechnique(nil, excludes)
+		if !ok {
+			t.Fatal("NextTechnique() found no technique")
+		}
+		for _, excluded := range excludes {
+			if string(got) == excluded {
+				t.Fatalf("NextTechnique() picked excluded technique %q", got)
+			}
+		}
+	}
+}
+
+func TestNextTechniqueFavoursWeakTechniques(t *testing.T) {
+	var excludes []string
+	for _, id := range technique.IDs() {
+		if id != types.TypoSquatting && id != types.TLDSwap {
+			excludes = append(excludes, string(id))
+		}
+	}
+	// typosquatting is mastered and weighs 0.1 against 1 for the unseen tld swap, so it should come up about 9% of the time
+	history := []types.MasteryDTO{{Technique: types.TypoSquatting, Seen: 9, Attempts: 9, Correct: 9}}
+	const draws = 2000
+	mastered := 0
+	for range draws {
+		got, _ := NextTechnique(history, excludes)
+		if got == types.TypoSquatting {
+			mastered++
+		}
+	}
+	if mastered == 0 || mastered > draws/5 {
+		t.Errorf("mastered technique picked %d out of %d times, want about %d", mastered, draws, draws/11)
+	}
+}
+
+func TestTechniqueWeights(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry types.MasteryDTO
+		want  float64
+	}{
+		{name: "unseen", entry: types.MasteryDTO{}, want: 1},
+		{name: "seen but never answered", entry: types.MasteryDTO{Seen: 3}, want: 0.25},
+		{name: "mastered", entry: types.MasteryDTO{Seen: 9, Attempts: 9, Correct: 9}, want: 0.1},
+		{name: "always missed", entry: types.MasteryDTO{Seen: 3, Attempts: 3, Correct: 0}, want: 1},
+		{name: "half missed", entry: types.MasteryDTO{Seen: 3, Attempts: 2, Correct: 1}, want: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entry.Technique = types.TypoSquatting
+			candidates, weights := techniqueWeights([]types.MasteryDTO{tt.entry}, nil)
+			if len(candidates) != technique.Count() {
+				t.Fatalf("techniqueWeights() returned %d candidates, want %d", len(candidates), technique.Count())
+			}
+			for i, candidate := range candidates {
+				want := 1.0
+				if candidate == types.TypoSquatting {
+					want = tt.want
+				}
+				if weights[i] != want {
+					t.Errorf("weight of %q = %v, want %v", candidate, weights[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestTechniqueWeightsExcludes(t *testing.T) {
+	excludes := []string{string(types.TypoSquatting), "made-up"}
+	candidates, weights := techniqueWeights(nil, excludes)
+	if len(candidates) != technique.Count()-1 || len(weights) != len(candidates) {
+		t.Fatalf("techniqueWeights() returned %d candidates and %d weights, want %d", len(candidates), len(weights), technique.Count()-1)
+	}
+	for _, candidate := range candidates {
+		if candidate == types.TypoSquatting {
+			t.Errorf("techniqueWeights() kept excluded technique %q", candidate)
+		}
+	}
+}
+
+func TestMastery(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		correct  int
+		want     float64
+	}{
+		{name: "never answered", attempts: 0, correct: 0, want: 0},
+		{name: "all correct", attempts: 4, correct: 4, want: 1},
+		{name: "half correct", attempts: 4, correct: 2, want: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mastery(tt.attempts, tt.correct); got != tt.want {
+				t.Errorf("Mastery(%d, %d) = %v, want %v", tt.attempts, tt.correct, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package learner
+
+import (
+	"database/sql"
+
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// TouchLearner() Inserts the learner into the database or refreshes when it was last seen
+func TouchLearner(db *sql.DB, id string) error {
+	upsertStmt := `INSERT INTO learners (id) VALUES ($1) ON CONFLICT (id) DO UPDATE SET last_seen_at = CURRENT_TIMESTAMP`
+	_, err := db.Exec(upsertStmt, id)
+	return err
+}
+
+// GetHistory() Retrieves every technique the learner has seen or answered from the database
+func GetHistory(db *sql.DB, id string) ([]types.MasteryDTO, error) {
+	getStmt := `SELECT technique, seen, attempts, correct FROM learner_techniques WHERE learner_id = ($1) ORDER BY technique`
+	rows, err := db.Query(getStmt, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	history := make([]types.MasteryDTO, 0)
+	for rows.Next() {
+		var entry types.MasteryDTO
+		if err := rows.Scan(&entry.Technique, &entry.Seen, &entry.Attempts, &entry.Correct); err != nil {
+			return nil, err
+		}
+		entry.Mastery = Mastery(entry.Attempts, entry.Correct)
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+// RecordSeen() Records in the database that the learner was shown a technique
+func RecordSeen(db *sql.DB, id string, technique types.PhishingTechnique) error {
+	if err := TouchLearner(db, id); err != nil {
+		return err
+	}
+	upsertStmt := `INSERT INTO learner_techniques (learner_id, technique, seen) VALUES ($1, $2, 1)
+		ON CONFLICT (learner_id, technique) DO UPDATE SET seen = learner_techniques.seen + 1, updated_at = CURRENT_TIMESTAMP`
+	_, err := db.Exec(upsertStmt, id, string(technique))
+	return err
+}
+
+// RecordAnswer() Records in the database whether the learner spotted a technique
+func RecordAnswer(db *sql.DB, id string, technique types.PhishingTechnique, correct bool) error {
+	if err := TouchLearner(db, id); err != nil {
+		return err
+	}
+	value := 0
+	if correct {
+		value = 1
+	}
+	upsertStmt := `INSERT INTO learner_techniques (learner_id, technique, attempts, correct) VALUES ($1, $2, 1, $3)
+		ON CONFLICT (learner_id, technique) DO UPDATE SET attempts = learner_techniques.attempts + 1,
+		correct = learner_techniques.correct + $3, updated_at = CURRENT_TIMESTAMP`
+	_, err := db.Exec(upsertStmt, id, string(technique), value)
+	return err
+}
+
+// DeleteHistory() Deletes every technique the learner has seen or answered from the database
+func DeleteHistory(db *sql.DB, id string) error {
+	deleteStmt := `DELETE FROM learner_techniques WHERE learner_id = ($1)`
+	_, err := db.Exec(deleteStmt, id)
+	return err
+}
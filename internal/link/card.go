@@ -0,0 +1,65 @@
+package link
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// const here stores the length limits of a link preview card
+const (
+	maxCardTitleLength = 200
+	maxCardImageLength = 2048
+)
+
+// cardTemplate renders the OpenGraph and Twitter card document served to unfurl bots instead of the redirect
+var cardTemplate = mustParseTemplate("templates/card.html")
+
+// Card represents the data rendered on the link preview shown by chat apps
+type Card struct {
+	Title       string
+	Description string
+	Image       string
+	URL         string
+}
+
+// NewCard() returns the link preview of the provided link, falling back to the configured title and image
+func NewCard(record Record) Card {
+	card := Card{
+		Title:       configs.Envs.CardTitle,
+		Description: configs.Envs.CardDescription,
+		Image:       configs.Envs.CardImage,
+		URL:         PrankURL(record.FakeLink),
+	}
+	if record.CardTitle != nil {
+		card.Title = *record.CardTitle
+	}
+	if record.CardImage != nil {
+		card.Image = *record.CardImage
+	}
+	return card
+}
+
+// RenderCard() writes the link preview document to the provided writer
+func RenderCard(writer io.Writer, card Card) error {
+	return cardTemplate.Execute(writer, card)
+}
+
+// ValidateCard() checks the optional link preview title and image of a link, "" means the configured one is used
+func ValidateCard(title string, image string) []types.FieldError {
+	var result types.ValidationResult
+	if utf8.RuneCountInString(title) > maxCardTitleLength {
+		result.Add("card_title", "INVALID_CARD_TITLE", fmt.Sprintf("The card title must be at most %d characters.", maxCardTitleLength), title)
+	}
+	if image != "" {
+		parsed, err := url.Parse(image)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(image) > maxCardImageLength {
+			result.Add("card_image", "INVALID_CARD_IMAGE", "The card image must be an absolute http or https URL.", image)
+		}
+	}
+	return result.Errors
+}
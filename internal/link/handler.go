@@ -3,37 +3,62 @@ package link
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/JBK2116/phakelinks/internal/click"
 	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/learner"
+	"github.com/JBK2116/phakelinks/internal/llm"
+	"github.com/JBK2116/phakelinks/internal/qr"
+	"github.com/JBK2116/phakelinks/internal/validator"
 	"github.com/JBK2116/phakelinks/types"
 	"github.com/gorilla/mux"
 )
 
-// LinkConn holds the database connection for link-related queries.
+// LinkConn holds the database connection, link store, LLM provider, link validator and click recorder for link-related queries.
 type LinkConn struct {
-	logger *slog.Logger
-	db     *sql.DB
+	logger    *slog.Logger
+	db        *sql.DB
+	store     LinkStore
+	llm       llm.Provider
+	validator validator.Validator
+	clicks    *click.Recorder
 }
 
-// NewLinkConn() creates a new LinkConn with the provided database connection.
-func NewLinkConn(logger *slog.Logger, db *sql.DB) *LinkConn {
+// NewLinkConn() creates a new LinkConn with the provided database connection, link store, LLM provider, link validator and click recorder.
+// The database connection is only used for learner sessions, links and quizzes live in the store.
+func NewLinkConn(logger *slog.Logger, db *sql.DB, store LinkStore, provider llm.Provider, v validator.Validator, clicks *click.Recorder) *LinkConn {
 	return &LinkConn{
-		logger: logger,
-		db:     db,
+		logger:    logger,
+		db:        db,
+		store:     store,
+		llm:       provider,
+		validator: v,
+		clicks:    clicks,
 	}
 }
 
 // RegisterRoutes() registers all routes for the LinkConn struct
 func (linkConn *LinkConn) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/links", linkConn.handleCreateLink).Methods("POST")
+	router.HandleFunc("/quiz/{id}/answer", linkConn.handleAnswerQuiz).Methods("POST")
+	router.HandleFunc("/links/{slug}", linkConn.handleGetLink).Methods("GET")
+	router.HandleFunc("/links/{slug}", linkConn.handleUpdateLink).Methods("PATCH")
+	router.HandleFunc("/links/{slug}", linkConn.handleDeleteLink).Methods("DELETE")
+	router.HandleFunc("/links/{slug}/qr", linkConn.handleGetQRCode).Methods("GET")
+	router.HandleFunc("/lessons/{id}", linkConn.handleGetSharedLesson).Methods("GET")
 }
 
 func (linkConn *LinkConn) RegisterRedirectRoutes(router *mux.Router) {
-	router.HandleFunc("/{path:.+}", linkConn.handleRedirect).Methods("GET")
+	router.HandleFunc("/preview/{slug:.+}", linkConn.handlePreview).Methods("GET", "HEAD")
+	router.HandleFunc("/{slug:.+}+", linkConn.handlePreview).Methods("GET", "HEAD")
+	router.HandleFunc("/lessons/{id}", linkConn.handleGetSharedLesson).Methods("GET", "HEAD")
+	router.HandleFunc("/{path:.+}", linkConn.handleRedirect).Methods("GET", "HEAD")
 }
 
 // handleCreateLink() handles the business logic for creating a new link
@@ -49,18 +74,43 @@ func (linkConn *LinkConn) handleCreateLink(writer http.ResponseWriter, request *
 	}
 	defer request.Body.Close()
 
-	if errStruct := ValidateCreateLinkDTO(dto); errStruct != nil {
+	if errStruct := ValidateCreateLinkDTO(linkConn.validator, dto); errStruct != nil {
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(writer).Encode(errStruct)
 		linkConn.logger.Info("Invalid CreateLinkDTO payload", slog.Any("error", errStruct))
 		return
 	}
+	canonicalLink, err := validator.Canonicalize(dto.Link)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "INVALID_URL", Message: "The URL or domain is not valid.", Value: err.Error()})
+		linkConn.logger.Info("Error canonicalizing link", slog.Any("error", err.Error()))
+		return
+	}
+	dto.Link = canonicalLink
+	learnerID := learner.SessionID(writer, request)
+	history, err := learner.GetHistory(linkConn.db, learnerID)
+	if err != nil {
+		linkConn.logger.Info("Error retrieving learner history from database", slog.Any("error", err.Error()))
+	}
 
 	var returnDTO types.ReturnLinkDTO
 	if dto.Mode == string(types.Educational) {
-		randPhishingTechnique := GetRandomPhishingTechnique(dto.Exclude)
-		explanationDTO, err := GetEducationalAISummary(randPhishingTechnique, dto.Link)
+		explanationDTO, model, err := PickEducationalSummary(request.Context(), linkConn.llm, dto.Link, dto.Exclude, history)
+		var upstreamErr *UpstreamError
+		if errors.As(err, &upstreamErr) {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusBadGateway)
+			json.NewEncoder(writer).Encode(types.ErrorResponse{
+				Error:   "UPSTREAM_ERROR",
+				Message: "The AI model failed to produce a valid educational summary. Please try again.",
+				Extra:   upstreamErr.Error(),
+			})
+			linkConn.logger.Error("Upstream model failed to create explanationDTO", slog.Any("error", upstreamErr.Error()))
+			return
+		}
 		if err != nil {
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
@@ -68,47 +118,558 @@ func (linkConn *LinkConn) handleCreateLink(writer http.ResponseWriter, request *
 			linkConn.logger.Info("Error creating explanationDTO", slog.Any("error", err.Error()))
 			return
 		}
+		if err := learner.RecordSeen(linkConn.db, learnerID, types.PhishingTechnique(explanationDTO.Technique)); err != nil {
+			linkConn.logger.Info("Error recording seen technique", slog.Any("error", err.Error()))
+		}
 		returnDTO.FakeLink = explanationDTO.FakeLink
 		returnDTO.Technique = explanationDTO.Technique
 		returnDTO.Explanation = explanationDTO.Explanation
-	} else {
-		prankDTO, err := GetPrankLink(dto.Link)
+		// the result is still returned when it cannot be saved, it just cannot be shared
+		lesson := NewSharedLesson(dto.Link, explanationDTO, model)
+		if err := linkConn.store.InsertSharedLesson(lesson); err != nil {
+			linkConn.logger.Error("Error saving shared lesson", slog.Any("error", err.Error()))
+		} else {
+			returnDTO.LessonID = lesson.ID
+			returnDTO.LessonURL = LessonURL(lesson.ID)
+		}
+	} else if dto.Mode == string(types.Quiz) {
+		quiz, err := NewQuiz(request.Context(), linkConn.llm, types.QuizFormat(dto.QuizFormat), dto.Link, dto.Exclude)
+		var upstreamErr *UpstreamError
+		if errors.As(err, &upstreamErr) {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusBadGateway)
+			json.NewEncoder(writer).Encode(types.ErrorResponse{
+				Error:   "UPSTREAM_ERROR",
+				Message: "The AI model failed to produce the quiz links. Please try again.",
+				Extra:   upstreamErr.Error(),
+			})
+			linkConn.logger.Error("Upstream model failed to create quiz", slog.Any("error", upstreamErr.Error()))
+			return
+		}
 		if err != nil {
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while generating the prank link. Please try again."})
-			linkConn.logger.Info("Error creating prankDTO", slog.Any("error", err.Error()))
+			json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while generating the quiz. Please try again."})
+			linkConn.logger.Info("Error creating quiz", slog.Any("error", err.Error()))
 			return
 		}
-		if err := InsertLink(linkConn.db, dto.Link, prankDTO.Slug); err != nil {
+		if err := linkConn.store.InsertQuiz(quiz); err != nil {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while creating the quiz. Please try again."})
+			linkConn.logger.Info("Error inserting quiz into database", slog.Any("error", err.Error()))
+			return
+		}
+		for _, choice := range quiz.Choices {
+			if choice.Real {
+				continue
+			}
+			if err := learner.RecordSeen(linkConn.db, learnerID, types.PhishingTechnique(choice.Technique)); err != nil {
+				linkConn.logger.Info("Error recording seen technique", slog.Any("error", err.Error()))
+			}
+		}
+		quizDTO := quiz.DTO()
+		returnDTO.Quiz = &quizDTO
+	} else {
+		slug := dto.Slug
+		if slug == "" {
+			prankDTO, err := GetPrankLink(linkConn.llm, dto.Link)
+			if err != nil {
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while generating the prank link. Please try again."})
+				linkConn.logger.Info("Error creating prankDTO", slog.Any("error", err.Error()))
+				return
+			}
+			slug = prankDTO.Slug
+		}
+		token, tokenHash := NewManagementToken()
+		record := Record{
+			TokenHash:      &tokenHash,
+			Link:           dto.Link,
+			Awareness:      dto.Awareness,
+			ActiveFrom:     utc(dto.ActiveFrom),
+			ExpiresAt:      utc(dto.ExpiresAt),
+			MaxClicks:      dto.MaxClicks,
+			RedirectStatus: dto.RedirectStatus,
+			CardTitle:      optional(dto.CardTitle),
+			CardImage:      optional(dto.CardImage),
+		}
+		if record.RedirectStatus == 0 {
+			record.RedirectStatus = defaultRedirectStatus
+		}
+		record, err := AllocateLink(linkConn.store, record, slug, dto.Slug != "")
+		if errors.Is(err, ErrSlugTaken) && dto.Slug != "" {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusConflict)
+			json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "SLUG_TAKEN", Message: "The slug already belongs to another link, please pick another one.", Value: dto.Slug})
+			linkConn.logger.Info("Vanity slug is taken", slog.String("slug", dto.Slug))
+			return
+		}
+		if err != nil {
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while creating the link. Please try again."})
 			linkConn.logger.Info("Error inserting link into database", slog.Any("error", err.Error()))
 			return
 		}
-		returnDTO.FakeLink = prankDTO.Link
+		returnDTO.FakeLink = PrankURL(record.FakeLink)
+		returnDTO.Slug = record.FakeLink
+		returnDTO.ManagementToken = token
+		returnDTO.Awareness = dto.Awareness
+		returnDTO.ActiveFrom = record.ActiveFrom
+		returnDTO.ExpiresAt = record.ExpiresAt
+		returnDTO.MaxClicks = record.MaxClicks
+		returnDTO.RedirectStatus = record.RedirectStatus
+		returnDTO.CardTitle = dto.CardTitle
+		returnDTO.CardImage = dto.CardImage
+	}
+	// the real link is the answer of a quiz so it is only revealed once the quiz is answered
+	if dto.Mode != string(types.Quiz) {
+		returnDTO.Link = dto.Link
 	}
-	returnDTO.Link = dto.Link
 	returnDTO.Mode = dto.Mode
+	if dto.QR != nil && returnDTO.FakeLink != "" {
+		code, err := NewQRCode(returnDTO.FakeLink, *dto.QR)
+		if err != nil {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while rendering the QR code. Please try again."})
+			linkConn.logger.Info("Error rendering QR code", slog.Any("error", err.Error()))
+			return
+		}
+		returnDTO.QR = code
+	}
 	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(http.StatusOK)
 	json.NewEncoder(writer).Encode(returnDTO)
 }
 
-// handleRedirect() handles the redirect server of the application
+// handleAnswerQuiz() grades the answer to a stored quiz and reveals the explanation of every fake link
+func (linkConn *LinkConn) handleAnswerQuiz(writer http.ResponseWriter, request *http.Request) {
+	id := mux.Vars(request)["id"]
+	var dto types.QuizAnswerDTO
+
+	if err := json.NewDecoder(request.Body).Decode(&dto); err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+		linkConn.logger.Error("Error decoding QuizAnswerDTO payload", slog.Any("error", err))
+		return
+	}
+	defer request.Body.Close()
+
+	quiz, err := linkConn.store.GetQuiz(id)
+	if errors.Is(err, ErrQuizNotFound) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "QUIZ_NOT_FOUND", Message: "The quiz does not exist.", Value: id})
+		linkConn.logger.Info("Quiz not found", slog.String("id", id))
+		return
+	}
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the quiz. Please try again."})
+		linkConn.logger.Info("Error retrieving quiz from database", slog.Any("error", err.Error()))
+		return
+	}
+	result, err := quiz.Grade(strings.TrimSpace(dto.Answer))
+	if err != nil {
+		var validation types.ValidationResult
+		validation.Add("answer", "INVALID_ANSWER", quiz.DTO().Question, dto.Answer)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(validation.ErrorResponse())
+		linkConn.logger.Info("Invalid QuizAnswerDTO payload", slog.Any("error", validation.Errors))
+		return
+	}
+	// only the first answer counts towards the mastery of the learner
+	first, err := linkConn.store.MarkQuizAnswered(id)
+	if err != nil {
+		linkConn.logger.Info("Error marking quiz as answered", slog.Any("error", err.Error()))
+	}
+	if first {
+		learnerID := learner.SessionID(writer, request)
+		for phishingTech, correct := range quiz.Attempts(result) {
+			if err := learner.RecordAnswer(linkConn.db, learnerID, phishingTech, correct); err != nil {
+				linkConn.logger.Info("Error recording quiz answer", slog.Any("error", err.Error()))
+			}
+		}
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(result)
+}
+
+// handlePreview() shows where a link leads, when it was created and whether it still works without redirecting or counting a click
+func (linkConn *LinkConn) handlePreview(writer http.ResponseWriter, request *http.Request) {
+	slug := mux.Vars(request)["slug"]
+	writer.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	record, err := linkConn.store.GetLink(slug)
+	if errors.Is(err, ErrLinkNotFound) {
+		linkConn.writeUnavailable(writer, request, StatusNotFound)
+		linkConn.logger.Info("Previewed link not found", slog.String("slug", slug))
+		return
+	}
+	if err != nil {
+		linkConn.writeRedirectError(writer, request, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(http.StatusOK)
+	if err := RenderPreview(writer, NewPreview(record, request.Host, time.Now().UTC())); err != nil {
+		linkConn.logger.Error("Error rendering preview page", slog.Any("error", err.Error()))
+	}
+	linkConn.logger.Info("Showing preview page", slog.String("slug", slug))
+}
+
+// handleGetSharedLesson() shows a saved educational result, as JSON if the client asked for it and as a web page otherwise
+func (linkConn *LinkConn) handleGetSharedLesson(writer http.ResponseWriter, request *http.Request) {
+	id := mux.Vars(request)["id"]
+	// the same address serves JSON and HTML, shared caches must not hand one the response of the other
+	writer.Header().Set("Vary", "Accept")
+	lesson, err := linkConn.store.GetSharedLesson(id)
+	if errors.Is(err, ErrLessonNotFound) {
+		writer.Header().Set("Cache-Control", "no-store")
+		if wantsJSON(request) {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "LESSON_NOT_FOUND", Message: "There is no lesson with this id.", Value: id})
+		} else {
+			writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+			writer.WriteHeader(http.StatusNotFound)
+			page := Unavailable{Title: "This lesson does not exist", Message: "There is no phakelinks lesson at this address, check that the link was copied in full.", Frontend: configs.Envs.FrontendHost}
+			if err := RenderUnavailable(writer, page); err != nil {
+				linkConn.logger.Error("Error rendering unavailable page", slog.Any("error", err.Error()))
+			}
+		}
+		linkConn.logger.Info("Shared lesson not found", slog.String("id", id))
+		return
+	}
+	if err != nil {
+		linkConn.writeRedirectError(writer, request, err)
+		return
+	}
+	// lessons never change once saved
+	writer.Header().Set("Cache-Control", "public, max-age=86400")
+	if wantsJSON(request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusOK)
+		json.NewEncoder(writer).Encode(lesson.DTO())
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+	if err := RenderSharedLesson(writer, NewSharedLessonPage(lesson)); err != nil {
+		linkConn.logger.Error("Error rendering shared lesson page", slog.Any("error", err.Error()))
+	}
+}
+
+// handleGetQRCode() returns the QR code of a prank link as an image.
+// The optional `format`, `level` and `size` query parameters fall back to the configured defaults.
+func (linkConn *LinkConn) handleGetQRCode(writer http.ResponseWriter, request *http.Request) {
+	slug := mux.Vars(request)["slug"]
+	query := request.URL.Query()
+
+	var result types.ValidationResult
+	size := 0
+	if value := query.Get("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			result.Add("size", "INVALID_QR_SIZE", "The QR code size must be an integer.", value)
+		}
+		size = parsed
+	}
+	// a size that is not an integer reaches NewOptions as 0 and takes the default, so its error is never reported twice
+	options, errs := qr.NewOptions(query.Get("format"), query.Get("level"), size)
+	result.Errors = append(result.Errors, errs...)
+	if !result.Valid() {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(result.ErrorResponse())
+		linkConn.logger.Info("Invalid QR code query", slog.Any("error", result.Errors))
+		return
+	}
+
+	record, err := linkConn.store.GetLink(slug)
+	if errors.Is(err, ErrLinkNotFound) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "LINK_NOT_FOUND", Message: "The link does not exist.", Value: slug})
+		linkConn.logger.Info("Link not found", slog.String("slug", slug))
+		return
+	}
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the link. Please try again."})
+		linkConn.logger.Info("Error retrieving link from database", slog.Any("error", err.Error()))
+		return
+	}
+	image, err := qr.Encode(PrankURL(record.FakeLink), options)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while rendering the QR code. Please try again."})
+		linkConn.logger.Info("Error rendering QR code", slog.Any("error", err.Error()))
+		return
+	}
+	writer.Header().Set("Content-Type", qr.ContentType(options.Format))
+	// the slug of a link never changes so neither does its QR code
+	writer.Header().Set("Cache-Control", "public, max-age=86400")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(image)
+}
+
+// authorizeLink() returns the link of the request if the request holds its management token, otherwise it writes the error response
+func (linkConn *LinkConn) authorizeLink(writer http.ResponseWriter, request *http.Request) (Record, bool) {
+	slug := mux.Vars(request)["slug"]
+	record, err := linkConn.store.GetLink(slug)
+	if errors.Is(err, ErrLinkNotFound) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "LINK_NOT_FOUND", Message: "The link does not exist.", Value: slug})
+		linkConn.logger.Info("Link not found", slog.String("slug", slug))
+		return record, false
+	}
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the link. Please try again."})
+		linkConn.logger.Info("Error retrieving link from database", slog.Any("error", err.Error()))
+		return record, false
+	}
+	token := bearerToken(request)
+	if token == "" {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("WWW-Authenticate", "Bearer")
+		writer.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "MISSING_TOKEN", Message: "The management token of the link is required."})
+		linkConn.logger.Info("Missing management token", slog.String("slug", slug))
+		return record, false
+	}
+	if !record.Authorized(token) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(writer).Encode(types.ErrorResponse{Error: "INVALID_TOKEN", Message: "The management token does not belong to this link."})
+		linkConn.logger.Info("Invalid management token", slog.String("slug", slug))
+		return record, false
+	}
+	return record, true
+}
+
+// ClickAuthorizer() returns the click.LinkAuthorizer that only lets the owner of a link read its analytics
+func (linkConn *LinkConn) ClickAuthorizer() click.LinkAuthorizer {
+	return func(writer http.ResponseWriter, request *http.Request) (int64, bool) {
+		record, ok := linkConn.authorizeLink(writer, request)
+		return record.ID, ok
+	}
+}
+
+// writeLink() writes the metadata of the link to the owner
+func (linkConn *LinkConn) writeLink(writer http.ResponseWriter, record Record) {
+	dto, err := NewLinkDTO(linkConn.store, record)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the link history. Please try again."})
+		linkConn.logger.Info("Error retrieving link versions from database", slog.Any("error", err.Error()))
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(dto)
+}
+
+// handleGetLink() returns the metadata and destination history of a link to its owner
+func (linkConn *LinkConn) handleGetLink(writer http.ResponseWriter, request *http.Request) {
+	record, ok := linkConn.authorizeLink(writer, request)
+	if !ok {
+		return
+	}
+	linkConn.writeLink(writer, record)
+}
+
+// handleUpdateLink() changes the destination or settings of a link for its owner
+func (linkConn *LinkConn) handleUpdateLink(writer http.ResponseWriter, request *http.Request) {
+	record, ok := linkConn.authorizeLink(writer, request)
+	if !ok {
+		return
+	}
+	var dto types.UpdateLinkDTO
+	if err := json.NewDecoder(request.Body).Decode(&dto); err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+		linkConn.logger.Error("Error decoding UpdateLinkDTO payload", slog.Any("error", err))
+		return
+	}
+	defer request.Body.Close()
+
+	changed, errStruct := ApplyUpdate(linkConn.validator, &record, dto)
+	if errStruct != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(writer).Encode(errStruct)
+		linkConn.logger.Info("Invalid UpdateLinkDTO payload", slog.Any("error", errStruct))
+		return
+	}
+	if err := linkConn.store.UpdateLink(record, changed); err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while updating the link. Please try again."})
+		linkConn.logger.Info("Error updating link in database", slog.Any("error", err.Error()))
+		return
+	}
+	record, err := linkConn.store.GetLink(record.FakeLink)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the link. Please try again."})
+		linkConn.logger.Info("Error retrieving link from database", slog.Any("error", err.Error()))
+		return
+	}
+	linkConn.writeLink(writer, record)
+}
+
+// handleDeleteLink() disables a link for its owner, it is purged by the Sweeper once the retention period passes
+func (linkConn *LinkConn) handleDeleteLink(writer http.ResponseWriter, request *http.Request) {
+	record, ok := linkConn.authorizeLink(writer, request)
+	if !ok {
+		return
+	}
+	if err := linkConn.store.DisableLink(record.ID); err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while disabling the link. Please try again."})
+		linkConn.logger.Info("Error disabling link in database", slog.Any("error", err.Error()))
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// utc() returns the provided time in UTC, the database stores timestamps without a time zone
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	converted := t.UTC()
+	return &converted
+}
+
+// optional() returns nil for "" so optional text settings are stored as NULL
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// deref() returns the provided string, or "" if there is none
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// handleRedirect() handles the redirect server of the application.
+// HEAD requests see the same status and headers as GET requests but never count as a click.
+// Unfurl bots of chat apps are served a link preview instead of the redirect and never use up the click limit.
+// Other bots use up the click limit like any visitor since they follow the redirect, but are kept out of the click analytics.
 func (linkConn *LinkConn) handleRedirect(writer http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	path := vars["path"]
-	originalLink, err := GetLink(linkConn.db, path)
-	if !strings.HasPrefix(originalLink, "https://") {
-		originalLink = fmt.Sprintf("https://%s", originalLink)
+	writer.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	record, err := linkConn.store.GetLink(path)
+	if errors.Is(err, ErrLinkNotFound) {
+		linkConn.writeUnavailable(writer, request, StatusNotFound)
+		linkConn.logger.Info("Link not found", slog.String("slug", path))
+		return
 	}
 	if err != nil {
-		linkConn.logger.Info("Error retrieving original link from database", slog.Any("error", err.Error()))
-		http.Redirect(writer, request, configs.Envs.FrontendHost, 308)
+		linkConn.writeRedirectError(writer, request, err)
+		return
+	}
+	// unfurl bots get a different response than visitors, shared caches must not hand one the response of the other
+	writer.Header().Set("Vary", "User-Agent")
+	status := record.Status(time.Now().UTC())
+	unfurl := click.IsUnfurlBot(request.UserAgent())
+	if status == StatusActive && record.MaxClicks != nil && request.Method != http.MethodHead && !unfurl {
+		claimed, err := linkConn.store.ClaimClick(record.ID)
+		if err != nil {
+			linkConn.logger.Error("Error counting click against the click limit", slog.Any("error", err.Error()))
+		} else if !claimed {
+			status = StatusExhausted
+		}
+	}
+	if status != StatusActive {
+		linkConn.writeUnavailable(writer, request, status)
+		linkConn.logger.Info("Link is not active", slog.String("slug", path), slog.String("status", string(status)))
+		return
+	}
+	if unfurl {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writer.Header().Set("Cache-Control", "no-store")
+		writer.WriteHeader(http.StatusOK)
+		if err := RenderCard(writer, NewCard(record)); err != nil {
+			linkConn.logger.Error("Error rendering link preview", slog.Any("error", err.Error()))
+		}
+		linkConn.logger.Info("Serving link preview to unfurl bot", slog.String("slug", path), slog.String("user_agent", request.UserAgent()))
+		return
+	}
+	if request.Method != http.MethodHead && click.ClassifyUserAgent(request.UserAgent()) != click.UABot {
+		linkConn.clicks.Record(click.NewClick(request, record.ID))
+	}
+	originalLink := record.Destination()
+	if record.Awareness {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writer.Header().Set("Cache-Control", "no-store")
+		writer.WriteHeader(http.StatusOK)
+		if err := RenderLesson(writer, NewLesson(path, request.Host, originalLink)); err != nil {
+			linkConn.logger.Error("Error rendering lesson page", slog.Any("error", err.Error()))
+		}
+		linkConn.logger.Info("Showing lesson page", slog.String("url", originalLink))
 		return
 	}
-	linkConn.logger.Info("Redirecting User", slog.String("url", originalLink))
-	http.Redirect(writer, request, originalLink, 308)
+	writer.Header().Set("Cache-Control", record.CacheControl(time.Now().UTC()))
+	linkConn.logger.Info("Redirecting User", slog.String("url", originalLink), slog.Int("status", record.RedirectStatus))
+	http.Redirect(writer, request, originalLink, record.RedirectStatus)
+}
+
+// writeUnavailable() explains why a link does not redirect, as JSON if the client asked for it and as a web page otherwise
+func (linkConn *LinkConn) writeUnavailable(writer http.ResponseWriter, request *http.Request, status LinkStatus) {
+	code, response := UnavailableError(status)
+	writer.Header().Set("Cache-Control", "no-store")
+	if wantsJSON(request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(code)
+		json.NewEncoder(writer).Encode(response)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(code)
+	if err := RenderUnavailable(writer, NewUnavailable(status)); err != nil {
+		linkConn.logger.Error("Error rendering unavailable page", slog.Any("error", err.Error()))
+	}
+}
+
+// writeRedirectError() reports a failure to look up a link, as JSON if the client asked for it and as a web page otherwise
+func (linkConn *LinkConn) writeRedirectError(writer http.ResponseWriter, request *http.Request, err error) {
+	linkConn.logger.Error("Error retrieving original link from database", slog.Any("error", err.Error()))
+	writer.Header().Set("Cache-Control", "no-store")
+	if wantsJSON(request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(writer).Encode(map[string]string{"error": err.Error(), "message": "Something went wrong while retrieving the link. Please try again."})
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(http.StatusInternalServerError)
+	page := Unavailable{Title: "Something went wrong", Message: "The link could not be looked up right now. Please try again in a moment.", Frontend: configs.Envs.FrontendHost}
+	if err := RenderUnavailable(writer, page); err != nil {
+		linkConn.logger.Error("Error rendering unavailable page", slog.Any("error", err.Error()))
+	}
 }
@@ -0,0 +1,502 @@
+package link
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/click"
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/llm"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/internal/validator"
+	"github.com/JBK2116/phakelinks/migrations"
+	"github.com/JBK2116/phakelinks/types"
+	"github.com/gorilla/mux"
+)
+
+func TestHandleRedirect(t *testing.T) {
+	past, future := time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour)
+	const (
+		browser = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"
+		unfurl  = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+		bot     = "curl/8.5.0"
+	)
+	tests := []struct {
+		name      string
+		record    *Record
+		method    string
+		userAgent string
+		accept    string
+		// wantCode is the status code of the response, and wantError the error of its JSON body if the client asked for JSON
+		wantCode  int
+		wantError string
+		// wantHeaders holds headers the response must carry
+		wantHeaders map[string]string
+		// wantClaimed is the number of clicks counted against the click limit, and wantRecorded the number kept for analytics
+		wantClaimed  int
+		wantRecorded int
+	}{
+		{
+			name:        "unknown slug",
+			method:      http.MethodGet,
+			userAgent:   browser,
+			accept:      "application/json",
+			wantCode:    http.StatusNotFound,
+			wantError:   "LINK_NOT_FOUND",
+			wantHeaders: map[string]string{"Cache-Control": "no-store"},
+		},
+		{
+			name:         "temporary redirect",
+			record:       &Record{RedirectStatus: http.StatusFound},
+			method:       http.MethodGet,
+			userAgent:    browser,
+			wantCode:     http.StatusFound,
+			wantHeaders:  map[string]string{"Location": "https://example.com", "Cache-Control": "no-store", "Vary": "User-Agent"},
+			wantRecorded: 1,
+		},
+		{
+			name:         "permanent redirect",
+			record:       &Record{RedirectStatus: http.StatusMovedPermanently},
+			method:       http.MethodGet,
+			userAgent:    browser,
+			wantCode:     http.StatusMovedPermanently,
+			wantHeaders:  map[string]string{"Location": "https://example.com", "Cache-Control": "public, max-age=86400"},
+			wantRecorded: 1,
+		},
+		{
+			name:        "head request",
+			record:      &Record{RedirectStatus: http.StatusFound, MaxClicks: ptr(1)},
+			method:      http.MethodHead,
+			userAgent:   browser,
+			wantCode:    http.StatusFound,
+			wantHeaders: map[string]string{"Location": "https://example.com"},
+		},
+		{
+			name:         "last click",
+			record:       &Record{RedirectStatus: http.StatusFound, MaxClicks: ptr(1)},
+			method:       http.MethodGet,
+			userAgent:    browser,
+			wantCode:     http.StatusFound,
+			wantClaimed:  1,
+			wantRecorded: 1,
+		},
+		{
+			name:      "clicks used up",
+			record:    &Record{RedirectStatus: http.StatusFound, MaxClicks: ptr(1), ClickCount: 1},
+			method:    http.MethodGet,
+			userAgent: browser,
+			accept:    "application/json",
+			wantCode:  http.StatusGone,
+			wantError: "LINK_EXHAUSTED",
+		},
+		{
+			name:      "expired",
+			record:    &Record{RedirectStatus: http.StatusFound, ExpiresAt: &past},
+			method:    http.MethodGet,
+			userAgent: browser,
+			accept:    "application/json",
+			wantCode:  http.StatusGone,
+			wantError: "LINK_EXPIRED",
+		},
+		{
+			name:      "pending",
+			record:    &Record{RedirectStatus: http.StatusFound, ActiveFrom: &future},
+			method:    http.MethodGet,
+			userAgent: browser,
+			accept:    "application/json",
+			wantCode:  http.StatusNotFound,
+			wantError: "LINK_PENDING",
+		},
+		{
+			name:      "disabled",
+			record:    &Record{RedirectStatus: http.StatusFound, DisabledAt: &past},
+			method:    http.MethodGet,
+			userAgent: browser,
+			accept:    "application/json",
+			wantCode:  http.StatusGone,
+			wantError: "LINK_DISABLED",
+		},
+		{
+			name:      "unavailable page",
+			record:    &Record{RedirectStatus: http.StatusFound, ExpiresAt: &past},
+			method:    http.MethodGet,
+			userAgent: browser,
+			accept:    "text/html",
+			wantCode:  http.StatusGone,
+			wantHeaders: map[string]string{
+				"Content-Type": "text/html; charset=utf-8",
+			},
+		},
+		{
+			name:        "unfurl bot",
+			record:      &Record{RedirectStatus: http.StatusFound, MaxClicks: ptr(1)},
+			method:      http.MethodGet,
+			userAgent:   unfurl,
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Content-Type": "text/html; charset=utf-8", "Vary": "User-Agent"},
+		},
+		{
+			name:        "other bot",
+			record:      &Record{RedirectStatus: http.StatusFound, MaxClicks: ptr(1)},
+			method:      http.MethodGet,
+			userAgent:   bot,
+			wantCode:    http.StatusFound,
+			wantHeaders: map[string]string{"Location": "https://example.com"},
+			wantClaimed: 1,
+		},
+		{
+			name:         "awareness lesson",
+			record:       &Record{RedirectStatus: http.StatusFound, Awareness: true},
+			method:       http.MethodGet,
+			userAgent:    browser,
+			wantCode:     http.StatusOK,
+			wantHeaders:  map[string]string{"Content-Type": "text/html; charset=utf-8", "Cache-Control": "no-store"},
+			wantRecorded: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			db := newClickDB(t, logger)
+			store := NewMemoryStore()
+			clicks := click.NewRecorder(logger, db)
+			if tt.record != nil {
+				tt.record.FakeLink = "free-gift"
+				tt.record.Link = "https://example.com"
+				if err := store.InsertLink(*tt.record); err != nil {
+					t.Fatal(err)
+				}
+			}
+			router := mux.NewRouter()
+			NewLinkConn(logger, db, store, nil, nil, clicks).RegisterRedirectRoutes(router)
+
+			request := httptest.NewRequest(tt.method, "/free-gift", nil)
+			request.Header.Set("User-Agent", tt.userAgent)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+			clicks.Close()
+
+			if response.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", response.Code, tt.wantCode)
+			}
+			if response.Header().Get("X-Robots-Tag") == "" {
+				t.Errorf("X-Robots-Tag header is missing")
+			}
+			for key, want := range tt.wantHeaders {
+				if got := response.Header().Get(key); got != want {
+					t.Errorf("%s header = %q, want %q", key, got, want)
+				}
+			}
+			if tt.wantError != "" {
+				var body types.ErrorResponse
+				if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+					t.Fatalf("decoding error response: %v", err)
+				}
+				if body.Error != tt.wantError {
+					t.Errorf("error = %q, want %q", body.Error, tt.wantError)
+				}
+			}
+			if tt.method == http.MethodHead && response.Body.Len() != 0 {
+				t.Errorf("HEAD response has a body of %d bytes", response.Body.Len())
+			}
+			if tt.record != nil {
+				stored, err := store.GetLink("free-gift")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if claimed := stored.ClickCount - tt.record.ClickCount; claimed != tt.wantClaimed {
+					t.Errorf("claimed %d clicks, want %d", claimed, tt.wantClaimed)
+				}
+			}
+			var recorded int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM clicks`).Scan(&recorded); err != nil {
+				t.Fatal(err)
+			}
+			if recorded != tt.wantRecorded {
+				t.Errorf("recorded %d clicks, want %d", recorded, tt.wantRecorded)
+			}
+		})
+	}
+}
+
+func TestHandleGetQRCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		slug  string
+		query string
+		// wantCode is the status code of the response, wantType its content type and wantErrors the fields of its error response
+		wantCode   int
+		wantType   string
+		wantErrors []string
+	}{
+		{name: "defaults", slug: "free-gift", wantCode: http.StatusOK, wantType: "image/png"},
+		{name: "svg", slug: "free-gift", query: "?format=svg&level=h&size=128", wantCode: http.StatusOK, wantType: "image/svg+xml"},
+		{name: "unknown slug", slug: "missing", wantCode: http.StatusNotFound, wantType: "application/json"},
+		{name: "size is not an integer", slug: "free-gift", query: "?size=big", wantCode: http.StatusBadRequest, wantType: "application/json", wantErrors: []string{"size"}},
+		{name: "size out of range", slug: "free-gift", query: "?size=4096", wantCode: http.StatusBadRequest, wantType: "application/json", wantErrors: []string{"size"}},
+		{
+			name:       "every parameter invalid",
+			slug:       "free-gift",
+			query:      "?format=gif&level=Z&size=big",
+			wantCode:   http.StatusBadRequest,
+			wantType:   "application/json",
+			wantErrors: []string{"size", "format", "level"},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := NewMemoryStore()
+	if err := store.InsertLink(Record{FakeLink: "free-gift", Link: "https://example.com", RedirectStatus: http.StatusFound}); err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	NewLinkConn(logger, nil, store, nil, nil, nil).RegisterRoutes(router)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/links/"+tt.slug+"/qr"+tt.query, nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", response.Code, tt.wantCode)
+			}
+			if got := response.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type header = %q, want %q", got, tt.wantType)
+			}
+			if tt.wantCode != http.StatusBadRequest {
+				return
+			}
+			var body types.ErrorResponse
+			if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding error response: %v", err)
+			}
+			var fields []string
+			for _, fieldErr := range body.Errors {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !slices.Equal(fields, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", fields, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestHandleCreateEducationalLink(t *testing.T) {
+	mode := configs.Envs.GeneratorMode
+	configs.Envs.GeneratorMode = GeneratorLLM
+	t.Cleanup(func() { configs.Envs.GeneratorMode = mode })
+	var exclude []string
+	for _, id := range technique.IDs() {
+		if id != types.TLDSwap {
+			exclude = append(exclude, string(id))
+		}
+	}
+	valid := `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `"}`
+	invalid := `{"fake_link": "https://paypal.net", "explanation": "Too short."}`
+	tests := []struct {
+		name      string
+		responses []llm.StubResponse
+		wantCode  int
+		wantError string
+	}{
+		{name: "valid summary", responses: []llm.StubResponse{{Text: valid}}, wantCode: http.StatusOK},
+		{name: "valid after a retry", responses: []llm.StubResponse{{Text: invalid}, {Text: valid}}, wantCode: http.StatusOK},
+		{name: "three invalid summaries", responses: []llm.StubResponse{{Text: invalid}, {Text: invalid}, {Text: invalid}}, wantCode: http.StatusBadGateway, wantError: "UPSTREAM_ERROR"},
+		{name: "provider error", responses: []llm.StubResponse{{Err: errors.New("rate limited")}}, wantCode: http.StatusBadGateway, wantError: "UPSTREAM_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			router := mux.NewRouter()
+			NewLinkConn(logger, newClickDB(t, logger), NewMemoryStore(), llm.NewStubProvider(tt.responses...), validator.NewLocalValidator(), nil).RegisterRoutes(router)
+
+			payload, err := json.Marshal(types.CreateLinkDTO{Link: "https://paypal.com", Mode: string(types.Educational), Exclude: exclude})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(http.MethodPost, "/links", bytes.NewReader(payload))
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", response.Code, tt.wantCode, response.Body)
+			}
+			if tt.wantError != "" {
+				var body types.ErrorResponse
+				if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+					t.Fatalf("decoding error response: %v", err)
+				}
+				if body.Error != tt.wantError {
+					t.Errorf("error = %q, want %q", body.Error, tt.wantError)
+				}
+				return
+			}
+			var body types.ReturnLinkDTO
+			if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if body.FakeLink != "https://paypal.net" || body.Technique != string(types.TLDSwap) || body.LessonID == "" {
+				t.Errorf("response = %+v", body)
+			}
+		})
+	}
+}
+
+func TestHandleCreateQuiz(t *testing.T) {
+	mode := configs.Envs.GeneratorMode
+	configs.Envs.GeneratorMode = GeneratorOffline
+	t.Cleanup(func() { configs.Envs.GeneratorMode = mode })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := NewMemoryStore()
+	router := mux.NewRouter()
+	NewLinkConn(logger, newClickDB(t, logger), store, llm.NewStubProvider(), validator.NewLocalValidator(), nil).RegisterRoutes(router)
+
+	payload, err := json.Marshal(types.CreateLinkDTO{Link: "https://paypal.com", Mode: string(types.Quiz), QuizFormat: string(types.PickReal)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/links", bytes.NewReader(payload)))
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", response.Code, http.StatusOK, response.Body)
+	}
+
+	// the challenge may only hold the plain choices, anything else would give the answer away
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	for _, field := range []string{"link", "fake_link", "technique", "explanation"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("response reveals %q: %s", field, body[field])
+		}
+	}
+	var challenge map[string]json.RawMessage
+	if err := json.Unmarshal(body["quiz"], &challenge); err != nil {
+		t.Fatalf("decoding quiz: %v", err)
+	}
+	for field := range challenge {
+		if !slices.Contains([]string{"id", "format", "question", "choices"}, field) {
+			t.Errorf("quiz reveals %q: %s", field, challenge[field])
+		}
+	}
+	var quizDTO types.QuizDTO
+	if err := json.Unmarshal(body["quiz"], &quizDTO); err != nil {
+		t.Fatalf("decoding quiz: %v", err)
+	}
+	if len(quizDTO.Choices) != quizFakes+1 || !slices.Contains(quizDTO.Choices, "https://paypal.com") {
+		t.Errorf("choices = %v, want the real link among %d choices", quizDTO.Choices, quizFakes+1)
+	}
+
+	// the stored quiz keeps the answer for grading
+	quiz, err := store.GetQuiz(quizDTO.ID)
+	if err != nil {
+		t.Fatalf("GetQuiz() error = %v", err)
+	}
+	if quiz.Link != "https://paypal.com" || len(quiz.Choices) != len(quizDTO.Choices) {
+		t.Errorf("stored quiz = %+v", quiz)
+	}
+}
+
+func TestHandleAnswerQuiz(t *testing.T) {
+	original := types.QuizChoiceDTO{Link: "https://paypal.com", Real: true}
+	fake := types.QuizChoiceDTO{Link: "https://paypal.net", Technique: string(types.TLDSwap), Explanation: explanation}
+	quizzes := []Quiz{
+		{ID: "pick", Format: types.PickReal, Link: original.Link, Choices: []types.QuizChoiceDTO{fake, original}},
+		{ID: "real", Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{original}},
+		{ID: "fake", Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{fake}},
+	}
+	tests := []struct {
+		name          string
+		id            string
+		body          string
+		wantCode      int
+		wantCorrect   bool
+		wantAnswer    string
+		wantError     string
+		wantErrFields []string
+	}{
+		{name: "real link picked", id: "pick", body: `{"answer": "https://paypal.com"}`, wantCode: http.StatusOK, wantCorrect: true, wantAnswer: original.Link},
+		{name: "answer is trimmed", id: "pick", body: `{"answer": " https://paypal.com "}`, wantCode: http.StatusOK, wantCorrect: true, wantAnswer: original.Link},
+		{name: "fake link picked", id: "pick", body: `{"answer": "https://paypal.net"}`, wantCode: http.StatusOK, wantCorrect: false, wantAnswer: original.Link},
+		{name: "real link called real", id: "real", body: `{"answer": "real"}`, wantCode: http.StatusOK, wantCorrect: true, wantAnswer: answerReal},
+		{name: "fake link called real", id: "fake", body: `{"answer": "real"}`, wantCode: http.StatusOK, wantCorrect: false, wantAnswer: answerFake},
+		{name: "answer that is not a choice", id: "pick", body: `{"answer": "https://example.com"}`, wantCode: http.StatusBadRequest, wantError: "INVALID_ANSWER", wantErrFields: []string{"answer"}},
+		{name: "link instead of real or fake", id: "fake", body: `{"answer": "https://paypal.net"}`, wantCode: http.StatusBadRequest, wantError: "INVALID_ANSWER", wantErrFields: []string{"answer"}},
+		{name: "unknown quiz", id: "missing", body: `{"answer": "real"}`, wantCode: http.StatusNotFound, wantError: "QUIZ_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			store := NewMemoryStore()
+			for _, quiz := range quizzes {
+				if err := store.InsertQuiz(quiz); err != nil {
+					t.Fatal(err)
+				}
+			}
+			router := mux.NewRouter()
+			NewLinkConn(logger, newClickDB(t, logger), store, llm.NewStubProvider(), validator.NewLocalValidator(), nil).RegisterRoutes(router)
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/quiz/"+tt.id+"/answer", bytes.NewReader([]byte(tt.body))))
+			if response.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", response.Code, tt.wantCode, response.Body)
+			}
+			if tt.wantError != "" {
+				var body types.ErrorResponse
+				if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+					t.Fatalf("decoding error response: %v", err)
+				}
+				var fields []string
+				for _, fieldErr := range body.Errors {
+					fields = append(fields, fieldErr.Field)
+				}
+				if body.Error != tt.wantError || !slices.Equal(fields, tt.wantErrFields) {
+					t.Errorf("error = %q on %v, want %q on %v", body.Error, fields, tt.wantError, tt.wantErrFields)
+				}
+				return
+			}
+			var result types.QuizResultDTO
+			if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.ID != tt.id || result.Correct != tt.wantCorrect || result.CorrectAnswer != tt.wantAnswer || result.Link != original.Link {
+				t.Errorf("result = %+v", result)
+			}
+			// answering reveals the lesson of every fake
+			for _, choice := range result.Choices {
+				if !choice.Real && (choice.Technique == "" || choice.Explanation == "") {
+					t.Errorf("fake choice %q is not explained", choice.Link)
+				}
+			}
+		})
+	}
+}
+
+// newClickDB() returns an in-memory SQLite database holding the schema of the memory backend
+func newClickDB(t *testing.T, logger *slog.Logger) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: opens a database of its own
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := migrations.Up(context.Background(), logger, db, configs.BackendMemory); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
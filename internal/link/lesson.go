@@ -0,0 +1,97 @@
+package link
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/JBK2116/phakelinks/internal/generator"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+// lessonTemplate renders the "you've been phished" page shown before an awareness link redirects
+var lessonTemplate = mustParseTemplate("templates/lesson.html")
+
+// mustParseTemplate() parses the provided embedded template and panics if it is malformed
+func mustParseTemplate(name string) *template.Template {
+	return template.Must(template.ParseFS(templates, name))
+}
+
+// urgencyWords holds words that pressure the reader into clicking without thinking
+var urgencyWords = []string{
+	"account", "alert", "confirm", "expire", "invoice", "locked", "login", "password", "payment",
+	"refund", "reset", "secure", "security", "signin", "suspended", "unlock", "update", "urgent", "verify", "winner",
+}
+
+// riskyExtensions holds file extensions that run code or hide other files when opened
+var riskyExtensions = []string{
+	".apk", ".bat", ".cmd", ".dmg", ".exe", ".html", ".iso", ".js", ".msi", ".ps1", ".rar", ".scr", ".vbs", ".zip",
+}
+
+// digitRun matches a run of digits that makes a slug look machine generated
+var digitRun = regexp.MustCompile(`\d{2,}`)
+
+// Lesson represents the data rendered on the lesson page of an awareness link
+type Lesson struct {
+	Slug        string
+	Host        string
+	Destination string
+	RedFlags    []string
+}
+
+// NewLesson() returns the lesson for the provided slug, the host it was served from and its real destination
+func NewLesson(slug string, host string, destination string) Lesson {
+	return Lesson{Slug: slug, Host: host, Destination: destination, RedFlags: RedFlags(slug, host, destination)}
+}
+
+// RenderLesson() writes the lesson page to the provided writer
+func RenderLesson(writer io.Writer, lesson Lesson) error {
+	return lessonTemplate.Execute(writer, lesson)
+}
+
+// RedFlags() returns every warning sign a careful reader could have spotted in the prank link
+func RedFlags(slug string, host string, destination string) []string {
+	flags := make([]string, 0)
+	lower := strings.ToLower(slug)
+	destinationHost := destination
+	brand := ""
+	if target, err := generator.ParseTarget(destination); err == nil {
+		destinationHost = target.Host
+		brand = target.Brand
+	}
+	if brand != "" && strings.Contains(lower, brand) {
+		flags = append(flags, fmt.Sprintf("The link mentions '%s' but it is hosted on %s, which has nothing to do with %s.", brand, host, destinationHost))
+	} else {
+		flags = append(flags, fmt.Sprintf("The link is hosted on %s, not on %s where it really leads.", host, destinationHost))
+	}
+	words := make([]string, 0)
+	for _, word := range urgencyWords {
+		if strings.Contains(lower, word) {
+			words = append(words, word)
+		}
+	}
+	if len(words) > 0 {
+		flags = append(flags, fmt.Sprintf("It uses pressure words (%s) to make you act before you think.", strings.Join(words, ", ")))
+	}
+	if ext := path.Ext(lower); ext != "" {
+		for _, risky := range riskyExtensions {
+			if ext == risky {
+				flags = append(flags, fmt.Sprintf("It ends in '%s', a file type that can run code on your device.", ext))
+				break
+			}
+		}
+	}
+	if digits := digitRun.FindString(slug); digits != "" {
+		flags = append(flags, fmt.Sprintf("Random numbers such as '%s' are a sign of a generated link.", digits))
+	}
+	if strings.Count(slug, "-") >= 3 {
+		flags = append(flags, "It strings many words together with hyphens, which real sites rarely do.")
+	}
+	return flags
+}
@@ -0,0 +1,163 @@
+package link
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// LinkStatus represents an enum type of whether a stored link currently redirects
+type LinkStatus string
+
+// const here stores all LinkStatus enums
+const (
+	StatusActive    LinkStatus = "active"
+	StatusPending   LinkStatus = "pending"
+	StatusExpired   LinkStatus = "expired"
+	StatusExhausted LinkStatus = "exhausted"
+	StatusDisabled  LinkStatus = "disabled"
+	// StatusNotFound is never stored, it describes a slug without a link
+	StatusNotFound LinkStatus = "not_found"
+)
+
+// unavailableTemplate renders the friendly page shown when a link does not redirect
+var unavailableTemplate = mustParseTemplate("templates/unavailable.html")
+
+// Unavailable represents the data rendered on the page of a link that does not redirect
+type Unavailable struct {
+	Title    string
+	Message  string
+	Frontend string
+}
+
+// Status() returns whether the link redirects at the provided time
+func (record Record) Status(now time.Time) LinkStatus {
+	switch {
+	case record.DisabledAt != nil:
+		return StatusDisabled
+	case record.ExpiresAt != nil && !now.Before(*record.ExpiresAt):
+		return StatusExpired
+	case record.ActiveFrom != nil && now.Before(*record.ActiveFrom):
+		return StatusPending
+	case record.MaxClicks != nil && record.ClickCount >= *record.MaxClicks:
+		return StatusExhausted
+	default:
+		return StatusActive
+	}
+}
+
+// NewUnavailable() returns the page explaining why a link with the provided status does not redirect
+func NewUnavailable(status LinkStatus) Unavailable {
+	page := Unavailable{Frontend: configs.Envs.FrontendHost}
+	switch status {
+	case StatusPending:
+		page.Title = "This link is not active yet"
+		page.Message = "The person who shared it scheduled it to start working later. Try again once it goes live."
+	case StatusExhausted:
+		page.Title = "This link has been used up"
+		page.Message = "It could only be opened a limited number of times and that limit has been reached."
+	case StatusDisabled:
+		page.Title = "This link has been disabled"
+		page.Message = "It no longer leads anywhere."
+	case StatusNotFound:
+		page.Title = "This link does not exist"
+		page.Message = "There is no phakelinks link at this address, so it does not lead anywhere."
+	default:
+		page.Title = "This link has expired"
+		page.Message = "The person who shared it gave it an expiry date, and that date has passed."
+	}
+	return page
+}
+
+// RenderUnavailable() writes the unavailable page to the provided writer
+func RenderUnavailable(writer io.Writer, page Unavailable) error {
+	return unavailableTemplate.Execute(writer, page)
+}
+
+// ValidateLifetime() checks that the optional activation window and click limit of a link make sense
+func ValidateLifetime(activeFrom *time.Time, expiresAt *time.Time, maxClicks *int, now time.Time) []types.FieldError {
+	var result types.ValidationResult
+	if expiresAt != nil && !expiresAt.After(now) {
+		result.Add("expires_at", "INVALID_EXPIRES_AT", "The expiry date must be in the future.", expiresAt.Format(time.RFC3339))
+	}
+	if activeFrom != nil && expiresAt != nil && !activeFrom.Before(*expiresAt) {
+		result.Add("active_from", "INVALID_ACTIVE_FROM", "The activation date must be before the expiry date.", activeFrom.Format(time.RFC3339))
+	}
+	if maxClicks != nil && *maxClicks < 1 {
+		result.Add("max_clicks", "INVALID_MAX_CLICKS", "The click limit must be at least 1.", fmt.Sprint(*maxClicks))
+	}
+	return result.Errors
+}
+
+// defaultSweepInterval is used when the configured sweep interval is not positive
+const defaultSweepInterval = time.Minute * 10
+
+// Sweeper periodically marks expired and exhausted links as swept and purges the clicks of links that have been dead for longer than the retention period
+type Sweeper struct {
+	logger    *slog.Logger
+	store     LinkStore
+	interval  time.Duration
+	retention time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewSweeper() creates a new Sweeper and starts sweeping in the background.
+// A non-positive interval falls back to `defaultSweepInterval`.
+func NewSweeper(logger *slog.Logger, store LinkStore, interval time.Duration, retention time.Duration) *Sweeper {
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+	sweeper := &Sweeper{
+		logger:    logger,
+		store:     store,
+		interval:  interval,
+		retention: retention,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go sweeper.run()
+	return sweeper
+}
+
+// Close() stops the Sweeper and waits for the current sweep to finish
+func (sweeper *Sweeper) Close() {
+	close(sweeper.stop)
+	<-sweeper.done
+}
+
+// run() sweeps once on start and then every interval until the Sweeper is closed
+func (sweeper *Sweeper) run() {
+	defer close(sweeper.done)
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+	for {
+		sweeper.Sweep(time.Now().UTC())
+		select {
+		case <-sweeper.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep() marks every dead link as swept and purges the clicks of links dead since before the retention period
+func (sweeper *Sweeper) Sweep(now time.Time) {
+	swept, err := sweeper.store.SweepDeadLinks(now)
+	if err != nil {
+		sweeper.logger.Error("Error sweeping dead links", slog.Any("error", err.Error()))
+		return
+	}
+	purged, err := sweeper.store.PurgeDeadLinks(now.Add(-sweeper.retention))
+	if err != nil {
+		sweeper.logger.Error("Error purging dead links", slog.Any("error", err.Error()))
+		return
+	}
+	if swept > 0 || purged > 0 {
+		sweeper.logger.Info("Swept links", slog.Int64("swept", swept), slog.Int64("purged_clicks", purged))
+	}
+}
@@ -0,0 +1,43 @@
+package link
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordStatus(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tests := []struct {
+		name   string
+		record Record
+		want   LinkStatus
+	}{
+		{name: "no limits", record: Record{}, want: StatusActive},
+		{name: "inside window", record: Record{ActiveFrom: &past, ExpiresAt: &future}, want: StatusActive},
+		{name: "before window", record: Record{ActiveFrom: &future}, want: StatusPending},
+		{name: "starts now", record: Record{ActiveFrom: &now}, want: StatusActive},
+		{name: "after window", record: Record{ExpiresAt: &past}, want: StatusExpired},
+		{name: "expires now", record: Record{ExpiresAt: &now}, want: StatusExpired},
+		{name: "clicks left", record: Record{MaxClicks: ptr(3), ClickCount: 2}, want: StatusActive},
+		{name: "clicks used up", record: Record{MaxClicks: ptr(3), ClickCount: 3}, want: StatusExhausted},
+		{name: "disabled", record: Record{DisabledAt: &past}, want: StatusDisabled},
+		{name: "disabled wins over expired", record: Record{DisabledAt: &past, ExpiresAt: &past}, want: StatusDisabled},
+		{name: "expired wins over pending", record: Record{ActiveFrom: &future, ExpiresAt: &past}, want: StatusExpired},
+		{name: "pending wins over exhausted", record: Record{ActiveFrom: &future, MaxClicks: ptr(1), ClickCount: 1}, want: StatusPending},
+		{name: "swept but extended", record: Record{SweptAt: &past, ExpiresAt: &future}, want: StatusActive},
+		{name: "swept and expired", record: Record{SweptAt: &past, ExpiresAt: &past}, want: StatusExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.Status(now); got != tt.want {
+				t.Errorf("Status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ptr() returns a pointer to the provided value
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,162 @@
+package link
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/validator"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// NewManagementToken() returns a new secret management token along with the hash that is stored in its place
+func NewManagementToken() (string, string) {
+	token := rand.Text()
+	return token, HashToken(token)
+}
+
+// HashToken() returns the SHA-256 hash of the provided management token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authorized() checks if the provided management token belongs to the link.
+// Links created before management tokens existed cannot be managed.
+func (record Record) Authorized(token string) bool {
+	if record.TokenHash == nil || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(*record.TokenHash), []byte(HashToken(token))) == 1
+}
+
+// bearerToken() returns the token of the `Authorization: Bearer` header, or "" if there is none
+func bearerToken(request *http.Request) string {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// NewLinkDTO() returns the metadata of the link as seen by its owner
+func NewLinkDTO(store LinkStore, record Record) (types.LinkDTO, error) {
+	versions, err := store.GetVersions(record.ID)
+	if err != nil {
+		return types.LinkDTO{}, err
+	}
+	return types.LinkDTO{
+		Slug:           record.FakeLink,
+		FakeLink:       PrankURL(record.FakeLink),
+		Link:           record.Link,
+		Status:         string(record.Status(time.Now().UTC())),
+		Awareness:      record.Awareness,
+		ActiveFrom:     record.ActiveFrom,
+		ExpiresAt:      record.ExpiresAt,
+		MaxClicks:      record.MaxClicks,
+		ClickCount:     record.ClickCount,
+		RedirectStatus: record.RedirectStatus,
+		CardTitle:      record.CardTitle,
+		CardImage:      record.CardImage,
+		CreatedAt:      record.CreatedAt,
+		UpdatedAt:      record.UpdatedAt,
+		DisabledAt:     record.DisabledAt,
+		Versions:       versions,
+	}, nil
+}
+
+// ApplyUpdate() validates the provided UpdateLinkDTO and applies it to the link.
+// It returns the ErrorResponse of every invalid field, or whether the destination changed.
+func ApplyUpdate(v validator.Validator, record *Record, dto types.UpdateLinkDTO) (bool, *types.ErrorResponse) {
+	var result types.ValidationResult
+	destination := record.Link
+	if dto.Link != nil {
+		if strings.TrimSpace(*dto.Link) == "" {
+			result.Add("link", "MISSING_URL", "A URL is required to change the destination.", "")
+		} else if err := ValidateLink(v, *dto.Link); err != nil {
+			result.Add("link", "INVALID_URL", "The URL or domain is not valid. Ensure it includes a scheme (e.g. https://) and a proper domain.", err.Error())
+		} else if canonicalLink, err := validator.Canonicalize(*dto.Link); err != nil {
+			result.Add("link", "INVALID_URL", "The URL or domain is not valid.", err.Error())
+		} else {
+			destination = canonicalLink
+		}
+	}
+	cleared, errs := clearedLimits(dto)
+	result.Errors = append(result.Errors, errs...)
+	activeFrom, expiresAt := record.ActiveFrom, record.ExpiresAt
+	if dto.ActiveFrom != nil {
+		activeFrom = utc(dto.ActiveFrom)
+	} else if cleared["active_from"] {
+		activeFrom = nil
+	}
+	if dto.ExpiresAt != nil {
+		expiresAt = utc(dto.ExpiresAt)
+	} else if cleared["expires_at"] {
+		expiresAt = nil
+	}
+	// the window is only checked when it changes so an expired link can still get a new destination
+	if dto.ActiveFrom != nil || dto.ExpiresAt != nil {
+		result.Errors = append(result.Errors, ValidateLifetime(activeFrom, expiresAt, dto.MaxClicks, time.Now())...)
+	} else {
+		result.Errors = append(result.Errors, ValidateLifetime(nil, nil, dto.MaxClicks, time.Now())...)
+	}
+	if dto.RedirectStatus != nil {
+		result.Errors = append(result.Errors, ValidateRedirectStatus(*dto.RedirectStatus)...)
+	}
+	if dto.CardTitle != nil || dto.CardImage != nil {
+		result.Errors = append(result.Errors, ValidateCard(deref(dto.CardTitle), deref(dto.CardImage))...)
+	}
+	if !result.Valid() {
+		return false, result.ErrorResponse()
+	}
+	changed := destination != record.Link
+	record.Link = destination
+	record.ActiveFrom = activeFrom
+	record.ExpiresAt = expiresAt
+	if dto.Awareness != nil {
+		record.Awareness = *dto.Awareness
+	}
+	if dto.MaxClicks != nil {
+		record.MaxClicks = dto.MaxClicks
+	} else if cleared["max_clicks"] {
+		record.MaxClicks = nil
+	}
+	if dto.RedirectStatus != nil {
+		record.RedirectStatus = *dto.RedirectStatus
+	}
+	if dto.CardTitle != nil {
+		record.CardTitle = optional(*dto.CardTitle)
+	}
+	if dto.CardImage != nil {
+		record.CardImage = optional(*dto.CardImage)
+	}
+	// a link that is live again must not be purged by the sweeper, it marks the link again if it is still dead
+	if status := record.Status(time.Now().UTC()); status != StatusExpired && status != StatusExhausted {
+		record.SweptAt = nil
+	}
+	return changed, nil
+}
+
+// clearedLimits() returns the limits the provided UpdateLinkDTO removes, a limit cannot be set and cleared by the same update
+func clearedLimits(dto types.UpdateLinkDTO) (map[string]bool, []types.FieldError) {
+	var result types.ValidationResult
+	set := map[string]bool{"active_from": dto.ActiveFrom != nil, "expires_at": dto.ExpiresAt != nil, "max_clicks": dto.MaxClicks != nil}
+	cleared := make(map[string]bool, len(dto.Clear))
+	for i, field := range dto.Clear {
+		alsoSet, ok := set[field]
+		switch {
+		case !ok:
+			result.Add(fmt.Sprintf("clear[%d]", i), "INVALID_CLEAR", "Only active_from, expires_at and max_clicks can be cleared.", field)
+		case alsoSet:
+			result.Add(fmt.Sprintf("clear[%d]", i), "INVALID_CLEAR", fmt.Sprintf("%s cannot be set and cleared at once.", field), field)
+		default:
+			cleared[field] = true
+		}
+	}
+	return cleared, result.Errors
+}
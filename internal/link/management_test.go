@@ -0,0 +1,174 @@
+package link
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/validator"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+func TestRecordAuthorized(t *testing.T) {
+	token, hash := NewManagementToken()
+	other, _ := NewManagementToken()
+	tests := []struct {
+		name   string
+		record Record
+		token  string
+		want   bool
+	}{
+		{name: "owner token", record: Record{TokenHash: &hash}, token: token, want: true},
+		{name: "other token", record: Record{TokenHash: &hash}, token: other, want: false},
+		{name: "empty token", record: Record{TokenHash: &hash}, token: "", want: false},
+		{name: "token hash instead of token", record: Record{TokenHash: &hash}, token: hash, want: false},
+		{name: "owner token with extra characters", record: Record{TokenHash: &hash}, token: token + "A", want: false},
+		{name: "link without token", record: Record{}, token: token, want: false},
+		{name: "link without token and empty token", record: Record{}, token: "", want: false},
+		{name: "empty hash", record: Record{TokenHash: ptr("")}, token: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.Authorized(tt.token); got != tt.want {
+				t.Errorf("Authorized(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+	}{
+		{name: "bearer token", authorization: "Bearer abc123", want: "abc123"},
+		{name: "surrounding whitespace", authorization: "Bearer  abc123 ", want: "abc123"},
+		{name: "no header", authorization: "", want: ""},
+		{name: "basic auth", authorization: "Basic dXNlcjpwYXNz", want: ""},
+		{name: "lowercase scheme", authorization: "bearer abc123", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/links/slug", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			if got := bearerToken(request); got != tt.want {
+				t.Errorf("bearerToken(%q) = %q, want %q", tt.authorization, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	past, soon, later := now.Add(-time.Hour), now.Add(time.Hour), now.Add(time.Hour*48)
+	tests := []struct {
+		name   string
+		record Record
+		dto    types.UpdateLinkDTO
+		// want holds the limits of the link after the update, wantErrors the fields of the error response if it is rejected
+		want       Record
+		wantErrors []string
+	}{
+		{
+			name:   "empty update keeps every limit",
+			record: Record{ActiveFrom: &past, ExpiresAt: &later, MaxClicks: ptr(5)},
+			want:   Record{ActiveFrom: &past, ExpiresAt: &later, MaxClicks: ptr(5)},
+		},
+		{
+			name:   "set limits",
+			record: Record{},
+			dto:    types.UpdateLinkDTO{ExpiresAt: &later, MaxClicks: ptr(3)},
+			want:   Record{ExpiresAt: &later, MaxClicks: ptr(3)},
+		},
+		{
+			name:   "clear every limit",
+			record: Record{ActiveFrom: &past, ExpiresAt: &later, MaxClicks: ptr(5)},
+			dto:    types.UpdateLinkDTO{Clear: []string{"active_from", "expires_at", "max_clicks"}},
+			want:   Record{},
+		},
+		{
+			name:   "clear one limit",
+			record: Record{ActiveFrom: &past, ExpiresAt: &later, MaxClicks: ptr(5)},
+			dto:    types.UpdateLinkDTO{Clear: []string{"expires_at"}},
+			want:   Record{ActiveFrom: &past, MaxClicks: ptr(5)},
+		},
+		{
+			name:   "clear the expiry of an expired link revives it",
+			record: Record{ExpiresAt: &past, SweptAt: &past},
+			dto:    types.UpdateLinkDTO{Clear: []string{"expires_at"}},
+			want:   Record{},
+		},
+		{
+			name:   "set one limit and clear another",
+			record: Record{ExpiresAt: &soon, MaxClicks: ptr(5)},
+			dto:    types.UpdateLinkDTO{ExpiresAt: &later, Clear: []string{"max_clicks"}},
+			want:   Record{ExpiresAt: &later},
+		},
+		{
+			name:       "clear an unknown field",
+			record:     Record{MaxClicks: ptr(5)},
+			dto:        types.UpdateLinkDTO{Clear: []string{"link"}},
+			wantErrors: []string{"clear[0]"},
+		},
+		{
+			name:       "set and clear the same limit",
+			record:     Record{MaxClicks: ptr(5)},
+			dto:        types.UpdateLinkDTO{MaxClicks: ptr(3), Clear: []string{"expires_at", "max_clicks"}},
+			wantErrors: []string{"clear[1]"},
+		},
+		{
+			name:       "invalid limits",
+			record:     Record{},
+			dto:        types.UpdateLinkDTO{ExpiresAt: &past, MaxClicks: ptr(0)},
+			wantErrors: []string{"expires_at", "max_clicks"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := tt.record
+			record.Link = "https://example.com"
+			_, errStruct := ApplyUpdate(validator.NewLocalValidator(), &record, tt.dto)
+			if len(tt.wantErrors) > 0 {
+				if errStruct == nil {
+					t.Fatalf("ApplyUpdate() accepted the update, want errors for %v", tt.wantErrors)
+				}
+				var fields []string
+				for _, fieldErr := range errStruct.Errors {
+					fields = append(fields, fieldErr.Field)
+				}
+				if !slices.Equal(fields, tt.wantErrors) {
+					t.Errorf("ApplyUpdate() errors = %v, want %v", fields, tt.wantErrors)
+				}
+				return
+			}
+			if errStruct != nil {
+				t.Fatalf("ApplyUpdate() rejected the update: %+v", errStruct)
+			}
+			if !equalTime(record.ActiveFrom, tt.want.ActiveFrom) {
+				t.Errorf("ActiveFrom = %v, want %v", record.ActiveFrom, tt.want.ActiveFrom)
+			}
+			if !equalTime(record.ExpiresAt, tt.want.ExpiresAt) {
+				t.Errorf("ExpiresAt = %v, want %v", record.ExpiresAt, tt.want.ExpiresAt)
+			}
+			if (record.MaxClicks == nil) != (tt.want.MaxClicks == nil) || (record.MaxClicks != nil && *record.MaxClicks != *tt.want.MaxClicks) {
+				t.Errorf("MaxClicks = %v, want %v", record.MaxClicks, tt.want.MaxClicks)
+			}
+			if !equalTime(record.SweptAt, tt.want.SweptAt) {
+				t.Errorf("SweptAt = %v, want %v", record.SweptAt, tt.want.SweptAt)
+			}
+		})
+	}
+}
+
+// equalTime() checks if the provided optional times are both unset or the same instant
+func equalTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
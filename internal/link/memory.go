@@ -0,0 +1,214 @@
+package link
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// MemoryStore is a LinkStore that keeps everything in memory, it is meant for tests and throwaway demos
+type MemoryStore struct {
+	mu       sync.Mutex
+	nextID   int64
+	links    map[string]*Record
+	slugs    map[int64]string
+	versions map[int64][]types.LinkVersionDTO
+	quizzes  map[string]*memoryQuiz
+	lessons  map[string]SharedLesson
+}
+
+// memoryQuiz represents a stored quiz and whether it was answered
+type memoryQuiz struct {
+	quiz     Quiz
+	answered bool
+}
+
+// NewMemoryStore() returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		links:    make(map[string]*Record),
+		slugs:    make(map[int64]string),
+		versions: make(map[int64][]types.LinkVersionDTO),
+		quizzes:  make(map[string]*memoryQuiz),
+		lessons:  make(map[string]SharedLesson),
+	}
+}
+
+// InsertLink() stores a link and the first version of its destination
+func (store *MemoryStore) InsertLink(record Record) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, ok := store.links[record.FakeLink]; ok {
+		return ErrSlugTaken
+	}
+	now := time.Now().UTC()
+	store.nextID++
+	record.ID = store.nextID
+	record.CreatedAt = now
+	record.UpdatedAt = now
+	store.links[record.FakeLink] = &record
+	store.slugs[record.ID] = record.FakeLink
+	store.addVersion(record.ID, record.Link, now)
+	return nil
+}
+
+// GetLink() returns a copy of the link with the provided slug
+func (store *MemoryStore) GetLink(slug string) (Record, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	record, ok := store.links[slug]
+	if !ok {
+		return Record{}, ErrLinkNotFound
+	}
+	return *record, nil
+}
+
+// UpdateLink() changes the settings of a link, recording a new version when its destination changed
+func (store *MemoryStore) UpdateLink(record Record, destinationChanged bool) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	stored := store.byID(record.ID)
+	if stored == nil {
+		return ErrLinkNotFound
+	}
+	now := time.Now().UTC()
+	stored.Link = record.Link
+	stored.Awareness = record.Awareness
+	stored.ActiveFrom = record.ActiveFrom
+	stored.ExpiresAt = record.ExpiresAt
+	stored.MaxClicks = record.MaxClicks
+	stored.RedirectStatus = record.RedirectStatus
+	stored.CardTitle = record.CardTitle
+	stored.CardImage = record.CardImage
+	stored.SweptAt = record.SweptAt
+	stored.UpdatedAt = now
+	if destinationChanged {
+		store.addVersion(record.ID, record.Link, now)
+	}
+	return nil
+}
+
+// GetVersions() returns every destination the link has had, oldest first
+func (store *MemoryStore) GetVersions(id int64) ([]types.LinkVersionDTO, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return slices.Clone(store.versions[id]), nil
+}
+
+// DisableLink() marks the link as disabled so it no longer redirects
+func (store *MemoryStore) DisableLink(id int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if record := store.byID(id); record != nil && record.DisabledAt == nil {
+		now := time.Now().UTC()
+		record.DisabledAt = &now
+		record.UpdatedAt = now
+	}
+	return nil
+}
+
+// ClaimClick() counts a click against the click limit of the link, returning false if the limit was already reached
+func (store *MemoryStore) ClaimClick(id int64) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	record := store.byID(id)
+	if record == nil || (record.MaxClicks != nil && record.ClickCount >= *record.MaxClicks) {
+		return false, nil
+	}
+	record.ClickCount++
+	record.UpdatedAt = time.Now().UTC()
+	return true, nil
+}
+
+// SweepDeadLinks() marks every expired or exhausted link as swept
+func (store *MemoryStore) SweepDeadLinks(now time.Time) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	var count int64
+	for _, record := range store.links {
+		if record.SweptAt != nil {
+			continue
+		}
+		status := record.Status(now)
+		if status != StatusExpired && status != StatusExhausted {
+			continue
+		}
+		sweptAt := now
+		record.SweptAt = &sweptAt
+		count++
+	}
+	return count, nil
+}
+
+// PurgeDeadLinks() keeps every link so its slug is never reused.
+// Clicks of the memory backend live in its in-memory database and are gone on restart, so there is nothing to purge.
+func (store *MemoryStore) PurgeDeadLinks(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// InsertQuiz() stores a quiz and its answer
+func (store *MemoryStore) InsertQuiz(quiz Quiz) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.quizzes[quiz.ID] = &memoryQuiz{quiz: quiz}
+	return nil
+}
+
+// GetQuiz() returns the quiz with the provided id
+func (store *MemoryStore) GetQuiz(id string) (Quiz, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	stored, ok := store.quizzes[id]
+	if !ok {
+		return Quiz{}, ErrQuizNotFound
+	}
+	return stored.quiz, nil
+}
+
+// MarkQuizAnswered() records that a quiz was answered, returning false if it was already answered
+func (store *MemoryStore) MarkQuizAnswered(id string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	stored, ok := store.quizzes[id]
+	if !ok || stored.answered {
+		return false, nil
+	}
+	stored.answered = true
+	return true, nil
+}
+
+// InsertSharedLesson() stores a saved educational result
+func (store *MemoryStore) InsertSharedLesson(lesson SharedLesson) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.lessons[lesson.ID] = lesson
+	return nil
+}
+
+// GetSharedLesson() returns the shared lesson with the provided id
+func (store *MemoryStore) GetSharedLesson(id string) (SharedLesson, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	lesson, ok := store.lessons[id]
+	if !ok {
+		return SharedLesson{}, ErrLessonNotFound
+	}
+	return lesson, nil
+}
+
+// byID() returns the stored link with the provided id, or nil. The caller must hold the lock.
+func (store *MemoryStore) byID(id int64) *Record {
+	slug, ok := store.slugs[id]
+	if !ok {
+		return nil
+	}
+	return store.links[slug]
+}
+
+// addVersion() appends a version to the destination history of a link. The caller must hold the lock.
+func (store *MemoryStore) addVersion(id int64, link string, changedAt time.Time) {
+	versions := store.versions[id]
+	store.versions[id] = append(versions, types.LinkVersionDTO{Version: len(versions) + 1, Link: link, ChangedAt: changedAt})
+}
@@ -0,0 +1,46 @@
+package link
+
+import (
+	"io"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+)
+
+// previewTemplate renders the page that shows where a link leads without redirecting
+var previewTemplate = mustParseTemplate("templates/preview.html")
+
+// Preview represents the data rendered on the preview page of a link
+type Preview struct {
+	Slug        string
+	Host        string
+	Destination string
+	CreatedAt   string
+	Awareness   bool
+	Active      bool
+	Status      string
+	Frontend    string
+}
+
+// NewPreview() returns the preview of the provided link as served from the provided host
+func NewPreview(record Record, host string, now time.Time) Preview {
+	preview := Preview{
+		Slug:        record.FakeLink,
+		Host:        host,
+		Destination: record.Destination(),
+		CreatedAt:   record.CreatedAt.UTC().Format("January 2, 2006 at 15:04 UTC"),
+		Awareness:   record.Awareness,
+		Frontend:    configs.Envs.FrontendHost,
+	}
+	status := record.Status(now)
+	preview.Active = status == StatusActive
+	if !preview.Active {
+		preview.Status = NewUnavailable(status).Title
+	}
+	return preview
+}
+
+// RenderPreview() writes the preview page to the provided writer
+func RenderPreview(writer io.Writer, preview Preview) error {
+	return previewTemplate.Execute(writer, preview)
+}
@@ -0,0 +1,192 @@
+package link
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/llm"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// quizFakes is the number of fake links shuffled in with the real link of a `types.PickReal` quiz
+const quizFakes = 3
+
+// maxQuizFailures is the number of fake links that may fail to generate before a quiz gives up on the rest
+const maxQuizFailures = 3
+
+// quizTimeout bounds the time spent generating every fake link of a quiz
+const quizTimeout = time.Minute * 1
+
+// const here stores the answers accepted by a `types.RealOrFake` quiz
+const (
+	answerReal = "real"
+	answerFake = "fake"
+)
+
+var (
+	// ErrInvalidAnswer is returned when a quiz answer is not one of the accepted answers
+	ErrInvalidAnswer = errors.New("answer is not one of the accepted answers")
+	// ErrNoQuizChoices is returned when no distinct fake link could be generated for a quiz
+	ErrNoQuizChoices = errors.New("no fake link could be generated for the quiz")
+)
+
+// Quiz represents a stored quiz challenge together with its answer
+type Quiz struct {
+	ID      string
+	Format  types.QuizFormat
+	Link    string
+	Choices []types.QuizChoiceDTO
+}
+
+// ValidateQuizFormat() checks that the provided quiz format is empty or a valid format defined in `types.go`
+func ValidateQuizFormat(format string) bool {
+	return format == "" || format == string(types.PickReal) || format == string(types.RealOrFake)
+}
+
+// NewQuiz() builds a quiz for the provided link using fake links from every technique not in excludes.
+// `types.PickReal` shuffles the real link among `quizFakes` fakes, `types.RealOrFake` shows the real link or a single fake at random.
+// The fakes are generated concurrently under a single `quizTimeout` deadline, and generation stops after `maxQuizFailures` failures.
+func NewQuiz(ctx context.Context, provider llm.Provider, format types.QuizFormat, link string, excludes []string) (Quiz, error) {
+	if format == "" {
+		format = types.PickReal
+	}
+	quiz := Quiz{ID: rand.Text(), Format: format, Link: link}
+	original := types.QuizChoiceDTO{Link: link, Real: true}
+	fakes := quizFakes
+	if format == types.RealOrFake {
+		if mathrand.IntN(2) == 0 {
+			quiz.Choices = []types.QuizChoiceDTO{original}
+			return quiz, nil
+		}
+		fakes = 1
+	}
+	available := make([]string, 0)
+	for _, v := range technique.IDs() {
+		if !contains(excludes, string(v)) {
+			available = append(available, string(v))
+		}
+	}
+	mathrand.Shuffle(len(available), func(i, j int) { available[i], available[j] = available[j], available[i] })
+
+	ctx, cancel := context.WithTimeout(ctx, quizTimeout)
+	defer cancel()
+	// the channel holds every result so generations still running when the quiz is done never block
+	results := make(chan quizFake, len(available))
+	next, running := 0, 0
+	generate := func() {
+		phishingTech := available[next]
+		next++
+		running++
+		go func() {
+			dto, _, err := GetEducationalSummary(ctx, provider, phishingTech, link)
+			results <- quizFake{dto: dto, err: err}
+		}()
+	}
+	for running < fakes && next < len(available) {
+		generate()
+	}
+	var lastErr error
+	failures := 0
+	for running > 0 {
+		result := <-results
+		running--
+		if result.err != nil {
+			lastErr = result.err
+			failures++
+		} else if result.dto.FakeLink != link && !containsChoice(quiz.Choices, result.dto.FakeLink) {
+			quiz.Choices = append(quiz.Choices, types.QuizChoiceDTO{Link: result.dto.FakeLink, Technique: result.dto.Technique, Explanation: result.dto.Explanation})
+		}
+		if len(quiz.Choices) == fakes || failures == maxQuizFailures || ctx.Err() != nil {
+			break
+		}
+		// only a failed or duplicate fake is replaced, so no more fakes are generated than the quiz needs
+		for running+len(quiz.Choices) < fakes && next < len(available) {
+			generate()
+		}
+	}
+	if len(quiz.Choices) == 0 {
+		if lastErr == nil {
+			lastErr = ErrNoQuizChoices
+		}
+		return quiz, lastErr
+	}
+	if format == types.PickReal {
+		quiz.Choices = append(quiz.Choices, original)
+		mathrand.Shuffle(len(quiz.Choices), func(i, j int) { quiz.Choices[i], quiz.Choices[j] = quiz.Choices[j], quiz.Choices[i] })
+	}
+	return quiz, nil
+}
+
+// quizFake represents the outcome of generating a single fake link of a quiz
+type quizFake struct {
+	dto types.ExplanationDTO
+	err error
+}
+
+// DTO() returns the quiz challenge without its answer
+func (quiz Quiz) DTO() types.QuizDTO {
+	dto := types.QuizDTO{ID: quiz.ID, Format: quiz.Format, Choices: make([]string, 0, len(quiz.Choices))}
+	for _, choice := range quiz.Choices {
+		dto.Choices = append(dto.Choices, choice.Link)
+	}
+	if quiz.Format == types.RealOrFake {
+		dto.Question = fmt.Sprintf("Is this link real or fake? Answer %q or %q.", answerReal, answerFake)
+	} else {
+		dto.Question = "Which of these links is the real one?"
+	}
+	return dto
+}
+
+// Grade() grades the provided answer, returning `ErrInvalidAnswer` if it is not one of the accepted answers
+func (quiz Quiz) Grade(answer string) (types.QuizResultDTO, error) {
+	result := types.QuizResultDTO{ID: quiz.ID, Answer: answer, Link: quiz.Link, Choices: quiz.Choices}
+	switch quiz.Format {
+	case types.RealOrFake:
+		if answer != answerReal && answer != answerFake {
+			return result, ErrInvalidAnswer
+		}
+		result.CorrectAnswer = answerFake
+		if quiz.Choices[0].Real {
+			result.CorrectAnswer = answerReal
+		}
+	default:
+		if !containsChoice(quiz.Choices, answer) {
+			return result, ErrInvalidAnswer
+		}
+		result.CorrectAnswer = quiz.Link
+	}
+	result.Correct = answer == result.CorrectAnswer
+	return result, nil
+}
+
+// Attempts() returns every fake technique of the quiz that the provided result tested and whether the learner spotted it.
+// Picking the real link spots every fake, picking a fake only misses that one.
+func (quiz Quiz) Attempts(result types.QuizResultDTO) map[types.PhishingTechnique]bool {
+	attempts := make(map[types.PhishingTechnique]bool)
+	for _, choice := range quiz.Choices {
+		if choice.Real {
+			continue
+		}
+		if quiz.Format == types.RealOrFake || result.Correct {
+			attempts[types.PhishingTechnique(choice.Technique)] = result.Correct
+		} else if choice.Link == result.Answer {
+			attempts[types.PhishingTechnique(choice.Technique)] = false
+		}
+	}
+	return attempts
+}
+
+// containsChoice() checks if the provided link is one of the choices
+func containsChoice(choices []types.QuizChoiceDTO, link string) bool {
+	for _, choice := range choices {
+		if choice.Link == link {
+			return true
+		}
+	}
+	return false
+}
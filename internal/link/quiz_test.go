@@ -0,0 +1,250 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/generator"
+	"github.com/JBK2116/phakelinks/internal/llm"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+func TestNewQuiz(t *testing.T) {
+	mode := configs.Envs.GeneratorMode
+	configs.Envs.GeneratorMode = GeneratorOffline
+	t.Cleanup(func() { configs.Envs.GeneratorMode = mode })
+
+	// character substitution, dot manipulation and hyphen insertion cannot be applied to a single letter brand
+	tests := []struct {
+		name           string
+		link           string
+		techniques     []types.PhishingTechnique
+		wantTechniques []types.PhishingTechnique
+		wantErr        error
+	}{
+		{
+			name:       "every technique available",
+			link:       "https://paypal.com",
+			techniques: technique.IDs(),
+		},
+		{
+			name:           "failures below the cutoff",
+			link:           "https://x.com",
+			techniques:     []types.PhishingTechnique{types.CharacterSub, types.DotManipulation, types.TLDSwap, types.ComboSquatting, types.LookAlikeDomain},
+			wantTechniques: []types.PhishingTechnique{types.ComboSquatting, types.LookAlikeDomain, types.TLDSwap},
+		},
+		{
+			name:       "every technique fails",
+			link:       "https://x.com",
+			techniques: []types.PhishingTechnique{types.CharacterSub, types.DotManipulation, types.HyphenInsertion},
+			wantErr:    generator.ErrNoVariant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiz, err := NewQuiz(context.Background(), llm.NewStubProvider(), types.PickReal, tt.link, excludeAllBut(tt.techniques...))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewQuiz() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewQuiz() error = %v", err)
+			}
+			if quiz.ID == "" || quiz.Format != types.PickReal || quiz.Link != tt.link {
+				t.Errorf("quiz = {id %q, format %q, link %q}", quiz.ID, quiz.Format, quiz.Link)
+			}
+			if len(quiz.Choices) != quizFakes+1 {
+				t.Fatalf("quiz has %d choices, want %d", len(quiz.Choices), quizFakes+1)
+			}
+			realChoices := 0
+			var techniques []types.PhishingTechnique
+			links := make(map[string]bool)
+			for _, choice := range quiz.Choices {
+				if links[choice.Link] {
+					t.Errorf("choice %q appears twice", choice.Link)
+				}
+				links[choice.Link] = true
+				if choice.Real {
+					realChoices++
+					if choice.Link != tt.link {
+						t.Errorf("real choice = %q, want %q", choice.Link, tt.link)
+					}
+					continue
+				}
+				if choice.Link == tt.link || choice.Explanation == "" || !slices.Contains(tt.techniques, types.PhishingTechnique(choice.Technique)) {
+					t.Errorf("fake choice = %+v", choice)
+				}
+				techniques = append(techniques, types.PhishingTechnique(choice.Technique))
+			}
+			if realChoices != 1 {
+				t.Errorf("quiz has %d real choices, want 1", realChoices)
+			}
+			if tt.wantTechniques != nil {
+				slices.Sort(techniques)
+				if !slices.Equal(techniques, tt.wantTechniques) {
+					t.Errorf("fake techniques = %v, want %v", techniques, tt.wantTechniques)
+				}
+			}
+		})
+	}
+}
+
+func TestNewQuizStopsAfterMaxFailures(t *testing.T) {
+	mode := configs.Envs.GeneratorMode
+	configs.Envs.GeneratorMode = GeneratorLLM
+	t.Cleanup(func() { configs.Envs.GeneratorMode = mode })
+
+	responses := make([]llm.StubResponse, technique.Count())
+	for i := range responses {
+		responses[i] = llm.StubResponse{Err: errors.New("rate limited")}
+	}
+	provider := llm.NewStubProvider(responses...)
+	_, err := NewQuiz(context.Background(), provider, types.PickReal, "https://paypal.com", nil)
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) {
+		t.Fatalf("NewQuiz() error = %v, want an UpstreamError", err)
+	}
+	// the first two failures each start a replacement that the quiz no longer waits for once the third failure arrives
+	want := quizFakes + maxQuizFailures - 1
+	deadline := time.Now().Add(5 * time.Second)
+	for len(provider.Requests()) < want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if requests := len(provider.Requests()); requests != want {
+		t.Errorf("sent %d requests, want %d of the %d techniques", requests, want, technique.Count())
+	}
+}
+
+func TestNewQuizGeneratesOnlyWhatItNeeds(t *testing.T) {
+	mode := configs.Envs.GeneratorMode
+	configs.Envs.GeneratorMode = GeneratorHybrid
+	t.Cleanup(func() { configs.Envs.GeneratorMode = mode })
+
+	// every fake asks the provider once and falls back to the offline generator, so the requests count the generated fakes
+	responses := make([]llm.StubResponse, technique.Count())
+	for i := range responses {
+		responses[i] = llm.StubResponse{Err: errors.New("rate limited")}
+	}
+	provider := llm.NewStubProvider(responses...)
+	excludes := excludeAllBut(types.TLDSwap, types.ComboSquatting, types.LookAlikeDomain, types.SubDomainAbuse, types.PathManipulation)
+	quiz, err := NewQuiz(context.Background(), provider, types.PickReal, "https://paypal.com", excludes)
+	if err != nil {
+		t.Fatalf("NewQuiz() error = %v", err)
+	}
+	if len(quiz.Choices) != quizFakes+1 {
+		t.Errorf("quiz has %d choices, want %d", len(quiz.Choices), quizFakes+1)
+	}
+	// a fake generated for nothing would still be running, so it is given a moment to reach the provider
+	time.Sleep(50 * time.Millisecond)
+	if requests := len(provider.Requests()); requests != quizFakes {
+		t.Errorf("sent %d requests, want %d", requests, quizFakes)
+	}
+}
+
+func TestNewQuizRealOrFake(t *testing.T) {
+	mode := configs.Envs.GeneratorMode
+	configs.Envs.GeneratorMode = GeneratorOffline
+	t.Cleanup(func() { configs.Envs.GeneratorMode = mode })
+
+	// the quiz shows the real link or a fake at random so it is built a few times
+	for range 20 {
+		quiz, err := NewQuiz(context.Background(), llm.NewStubProvider(), types.RealOrFake, "https://paypal.com", nil)
+		if err != nil {
+			t.Fatalf("NewQuiz() error = %v", err)
+		}
+		if len(quiz.Choices) != 1 {
+			t.Fatalf("quiz has %d choices, want 1", len(quiz.Choices))
+		}
+		choice := quiz.Choices[0]
+		if choice.Real != (choice.Link == "https://paypal.com") || (!choice.Real && choice.Technique == "") {
+			t.Errorf("choice = %+v", choice)
+		}
+	}
+}
+
+func TestQuizGrade(t *testing.T) {
+	original := types.QuizChoiceDTO{Link: "https://paypal.com", Real: true}
+	fake := types.QuizChoiceDTO{Link: "https://paypal.net", Technique: string(types.TLDSwap), Explanation: explanation}
+	pickReal := Quiz{ID: "pick", Format: types.PickReal, Link: original.Link, Choices: []types.QuizChoiceDTO{fake, original}}
+	tests := []struct {
+		name        string
+		quiz        Quiz
+		answer      string
+		wantCorrect bool
+		wantAnswer  string
+		wantErr     error
+	}{
+		{name: "pick the real link", quiz: pickReal, answer: original.Link, wantCorrect: true, wantAnswer: original.Link},
+		{name: "pick the fake link", quiz: pickReal, answer: fake.Link, wantCorrect: false, wantAnswer: original.Link},
+		{name: "pick a link that is not a choice", quiz: pickReal, answer: "https://example.com", wantErr: ErrInvalidAnswer},
+		{name: "real link called real", quiz: Quiz{Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{original}}, answer: answerReal, wantCorrect: true, wantAnswer: answerReal},
+		{name: "real link called fake", quiz: Quiz{Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{original}}, answer: answerFake, wantCorrect: false, wantAnswer: answerReal},
+		{name: "fake link called fake", quiz: Quiz{Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{fake}}, answer: answerFake, wantCorrect: true, wantAnswer: answerFake},
+		{name: "fake link called real", quiz: Quiz{Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{fake}}, answer: answerReal, wantCorrect: false, wantAnswer: answerFake},
+		{name: "link instead of real or fake", quiz: Quiz{Format: types.RealOrFake, Link: original.Link, Choices: []types.QuizChoiceDTO{fake}}, answer: fake.Link, wantErr: ErrInvalidAnswer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.quiz.Grade(tt.answer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Grade(%q) error = %v, want %v", tt.answer, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if result.Correct != tt.wantCorrect || result.CorrectAnswer != tt.wantAnswer || result.Link != original.Link {
+				t.Errorf("Grade(%q) = %+v", tt.answer, result)
+			}
+		})
+	}
+}
+
+func TestQuizAttempts(t *testing.T) {
+	original := types.QuizChoiceDTO{Link: "https://paypal.com", Real: true}
+	tld := types.QuizChoiceDTO{Link: "https://paypal.net", Technique: string(types.TLDSwap)}
+	typo := types.QuizChoiceDTO{Link: "https://paypa.com", Technique: string(types.TypoSquatting)}
+	quiz := Quiz{Format: types.PickReal, Link: original.Link, Choices: []types.QuizChoiceDTO{tld, original, typo}}
+	tests := []struct {
+		name   string
+		answer string
+		want   map[types.PhishingTechnique]bool
+	}{
+		{name: "real link spots every fake", answer: original.Link, want: map[types.PhishingTechnique]bool{types.TLDSwap: true, types.TypoSquatting: true}},
+		{name: "fake link only misses that fake", answer: typo.Link, want: map[types.PhishingTechnique]bool{types.TypoSquatting: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := quiz.Grade(tt.answer)
+			if err != nil {
+				t.Fatalf("Grade() error = %v", err)
+			}
+			got := quiz.Attempts(result)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Attempts() = %v, want %v", got, tt.want)
+			}
+			for phishingTech, correct := range tt.want {
+				if spotted, ok := got[phishingTech]; !ok || spotted != correct {
+					t.Errorf("Attempts() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// excludeAllBut() returns every technique except the provided ones
+func excludeAllBut(techniques ...types.PhishingTechnique) []string {
+	excludes := make([]string, 0)
+	for _, id := range technique.IDs() {
+		if !slices.Contains(techniques, id) {
+			excludes = append(excludes, string(id))
+		}
+	}
+	return excludes
+}
@@ -0,0 +1,82 @@
+package link
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// defaultRedirectStatus is used when a link is created without a redirect status.
+// A temporary redirect keeps browsers coming back, so edits, limits and click counts apply to every visit.
+const defaultRedirectStatus = http.StatusFound
+
+// maxPermanentCacheAge caps how long a permanent redirect may be cached by browsers and proxies
+const maxPermanentCacheAge = time.Hour * 24
+
+// redirectStatuses holds the status codes a link can redirect with, mapped to whether they are permanent
+var redirectStatuses = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             false,
+	http.StatusTemporaryRedirect: false,
+	http.StatusPermanentRedirect: true,
+}
+
+// ValidateRedirectStatus() checks that the provided status code is one a link can redirect with
+func ValidateRedirectStatus(code int) []types.FieldError {
+	var result types.ValidationResult
+	if _, ok := redirectStatuses[code]; !ok {
+		result.Add("redirect_status", "INVALID_REDIRECT_STATUS", "The redirect status must be 301, 302, 307 or 308.", fmt.Sprint(code))
+	}
+	return result.Errors
+}
+
+// CacheControl() returns the Cache-Control header of a redirect to the link at the provided time.
+// Only permanent redirects of links that cannot run out of clicks are cached, and never past their expiry,
+// so a changed destination or a disabled link reaches every visitor within `maxPermanentCacheAge`.
+func (record Record) CacheControl(now time.Time) string {
+	if !redirectStatuses[record.RedirectStatus] || record.MaxClicks != nil {
+		return "no-store"
+	}
+	age := maxPermanentCacheAge
+	if record.ExpiresAt != nil {
+		age = min(age, record.ExpiresAt.Sub(now))
+	}
+	if age < time.Second {
+		return "no-store"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(age.Seconds()))
+}
+
+// UnavailableError() returns the HTTP status code and error response of a link with the provided status that does not redirect
+func UnavailableError(status LinkStatus) (int, types.ErrorResponse) {
+	page := NewUnavailable(status)
+	response := types.ErrorResponse{Error: "LINK_" + strings.ToUpper(string(status)), Message: fmt.Sprintf("%s. %s", page.Title, page.Message)}
+	switch status {
+	case StatusNotFound, StatusPending:
+		return http.StatusNotFound, response
+	default:
+		return http.StatusGone, response
+	}
+}
+
+// wantsJSON() checks if the client asked for JSON rather than a web page
+func wantsJSON(request *http.Request) bool {
+	for _, accepted := range strings.Split(request.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		// the first supported type wins, browsers always list html first
+		switch mediaType {
+		case "application/json":
+			return true
+		case "text/html", "application/xhtml+xml":
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package link
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecordCacheControl(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		record Record
+		want   string
+	}{
+		{name: "temporary redirect", record: Record{RedirectStatus: http.StatusFound}, want: "no-store"},
+		{name: "temporary redirect that keeps the method", record: Record{RedirectStatus: http.StatusTemporaryRedirect}, want: "no-store"},
+		{name: "permanent redirect", record: Record{RedirectStatus: http.StatusMovedPermanently}, want: "public, max-age=86400"},
+		{name: "permanent redirect that keeps the method", record: Record{RedirectStatus: http.StatusPermanentRedirect}, want: "public, max-age=86400"},
+		{name: "permanent redirect with a click limit", record: Record{RedirectStatus: http.StatusMovedPermanently, MaxClicks: ptr(10)}, want: "no-store"},
+		{name: "permanent redirect expiring soon", record: Record{RedirectStatus: http.StatusMovedPermanently, ExpiresAt: ptr(now.Add(time.Hour))}, want: "public, max-age=3600"},
+		{name: "permanent redirect expiring later", record: Record{RedirectStatus: http.StatusMovedPermanently, ExpiresAt: ptr(now.Add(time.Hour * 48))}, want: "public, max-age=86400"},
+		{name: "permanent redirect expiring within a second", record: Record{RedirectStatus: http.StatusMovedPermanently, ExpiresAt: ptr(now.Add(time.Millisecond * 500))}, want: "no-store"},
+		{name: "permanent redirect already expired", record: Record{RedirectStatus: http.StatusMovedPermanently, ExpiresAt: ptr(now.Add(-time.Hour))}, want: "no-store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.CacheControl(now); got != tt.want {
+				t.Errorf("CacheControl() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWantsJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "no accept header", accept: "", want: false},
+		{name: "json", accept: "application/json", want: true},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: false},
+		{name: "json first", accept: "application/json, text/html", want: true},
+		{name: "html first", accept: "text/html, application/json", want: false},
+		{name: "anything", accept: "*/*", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/slug", nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			if got := wantsJSON(request); got != tt.want {
+				t.Errorf("wantsJSON(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,22 +2,253 @@ package link
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JBK2116/phakelinks/types"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
-// InsertLink() Inserts a link into the database
-func InsertLink(db *sql.DB, link string, fakeLink string) error {
-	insertStmt := `INSERT INTO links (link, fakelink) VALUES ($1, $2)`
-	_, err := db.Exec(insertStmt, link, fakeLink)
+// Record represents a stored prank link
+type Record struct {
+	ID         int64
+	Link       string
+	FakeLink   string
+	Awareness  bool
+	ActiveFrom *time.Time
+	ExpiresAt  *time.Time
+	MaxClicks  *int
+	ClickCount int
+	DisabledAt *time.Time
+	// SweptAt is when the sweeper found the link expired or used up, it is cleared once the link is live again
+	SweptAt   *time.Time
+	TokenHash *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// RedirectStatus is the HTTP status code the link redirects with
+	RedirectStatus int
+	// CardTitle and CardImage override the configured link preview shown to unfurl bots
+	CardTitle *string
+	CardImage *string
+}
+
+// sqlStore is the LinkStore of a SQL database, its queries run unchanged on Postgres and SQLite
+type sqlStore struct {
+	db *sql.DB
+	// isSlugTaken checks if an insert failed because of the unique constraint on `fakelink`
+	isSlugTaken func(err error) bool
+}
+
+// NewPostgresStore() returns the LinkStore of the provided Postgres database
+func NewPostgresStore(db *sql.DB) LinkStore {
+	return &sqlStore{db: db, isSlugTaken: func(err error) bool {
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "links_fakelink_key"
+	}}
+}
+
+// NewSQLiteStore() returns the LinkStore of the provided SQLite database
+func NewSQLiteStore(db *sql.DB) LinkStore {
+	return &sqlStore{db: db, isSlugTaken: func(err error) bool {
+		var sqliteErr *sqlite.Error
+		return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(sqliteErr.Error(), "links.fakelink")
+	}}
+}
+
+// InsertLink() Inserts a link and the first version of its destination into the database.
+// It returns ErrSlugTaken if another link already has the same slug.
+func (store *sqlStore) InsertLink(record Record) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	insertStmt := `INSERT INTO links (link, fakelink, awareness, active_from, expires_at, max_clicks, token_hash, redirect_status,
+		card_title, card_image) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	var id int64
+	if err := tx.QueryRow(insertStmt, record.Link, record.FakeLink, record.Awareness, record.ActiveFrom, record.ExpiresAt, record.MaxClicks, record.TokenHash, record.RedirectStatus,
+		record.CardTitle, record.CardImage).Scan(&id); err != nil {
+		if store.isSlugTaken(err) {
+			return ErrSlugTaken
+		}
+		return err
+	}
+	if err := insertVersion(tx, id, record.Link); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateLink() Updates the settings of a link in the database, recording a new version when its destination changed
+func (store *sqlStore) UpdateLink(record Record, destinationChanged bool) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	updateStmt := `UPDATE links SET link = $2, awareness = $3, active_from = $4, expires_at = $5, max_clicks = $6, redirect_status = $7,
+		card_title = $8, card_image = $9, swept_at = $10, updated_at = CURRENT_TIMESTAMP WHERE id = ($1)`
+	if _, err := tx.Exec(updateStmt, record.ID, record.Link, record.Awareness, record.ActiveFrom, record.ExpiresAt, record.MaxClicks, record.RedirectStatus,
+		record.CardTitle, record.CardImage, record.SweptAt); err != nil {
+		return err
+	}
+	if destinationChanged {
+		if err := insertVersion(tx, record.ID, record.Link); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertVersion() Inserts a new version of the destination of a link into the database
+func insertVersion(tx *sql.Tx, id int64, link string) error {
+	insertStmt := `INSERT INTO link_versions (link_id, link) VALUES ($1, $2)`
+	_, err := tx.Exec(insertStmt, id, link)
+	return err
+}
+
+// GetVersions() Retrieves every destination a link has had from the database, oldest first
+func (store *sqlStore) GetVersions(id int64) ([]types.LinkVersionDTO, error) {
+	getStmt := `SELECT link, changed_at FROM link_versions WHERE link_id = ($1) ORDER BY id`
+	rows, err := store.db.Query(getStmt, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := make([]types.LinkVersionDTO, 0)
+	for rows.Next() {
+		version := types.LinkVersionDTO{Version: len(versions) + 1}
+		if err := rows.Scan(&version.Link, &version.ChangedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// DisableLink() Marks a link in the database as disabled so it no longer redirects
+func (store *sqlStore) DisableLink(id int64) error {
+	updateStmt := `UPDATE links SET disabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ($1) AND disabled_at IS NULL`
+	_, err := store.db.Exec(updateStmt, id)
 	return err
 }
 
-// getLink() Retreives a link from the database with a matching target string
-func GetLink(db *sql.DB, target string) (string, error) {
-	getStmt := `SELECT link FROM links WHERE fakelink = ($1) LIMIT 1`
-	var link string
-	err := db.QueryRow(getStmt, target).Scan(&link)
+// GetLink() Retreives a link from the database with a matching target string
+func (store *sqlStore) GetLink(target string) (Record, error) {
+	getStmt := `SELECT id, link, fakelink, awareness, active_from, expires_at, max_clicks, click_count, disabled_at, swept_at,
+		token_hash, created_at, updated_at, redirect_status, card_title, card_image FROM links WHERE fakelink = ($1)`
+	var record Record
+	err := store.db.QueryRow(getStmt, target).Scan(&record.ID, &record.Link, &record.FakeLink, &record.Awareness,
+		&record.ActiveFrom, &record.ExpiresAt, &record.MaxClicks, &record.ClickCount, &record.DisabledAt, &record.SweptAt,
+		&record.TokenHash, &record.CreatedAt, &record.UpdatedAt, &record.RedirectStatus,
+		&record.CardTitle, &record.CardImage)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Record{}, ErrLinkNotFound
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	return record, nil
+}
+
+// ClaimClick() Counts a click against the click limit of a link, returning false if the limit was already reached
+func (store *sqlStore) ClaimClick(id int64) (bool, error) {
+	updateStmt := `UPDATE links SET click_count = click_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ($1) AND (max_clicks IS NULL OR click_count < max_clicks)`
+	result, err := store.db.Exec(updateStmt, id)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+// SweepDeadLinks() Marks every expired or exhausted link in the database as swept, leaving `disabled_at` to owners
+func (store *sqlStore) SweepDeadLinks(now time.Time) (int64, error) {
+	updateStmt := `UPDATE links SET swept_at = $1 WHERE swept_at IS NULL AND disabled_at IS NULL
+		AND (expires_at <= $1 OR (max_clicks IS NOT NULL AND click_count >= max_clicks))`
+	result, err := store.db.Exec(updateStmt, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeDeadLinks() Deletes the clicks of every link in the database that was disabled or swept before the provided time.
+// The link and its destination history are kept so its slug is never handed out again.
+func (store *sqlStore) PurgeDeadLinks(before time.Time) (int64, error) {
+	deleteStmt := `DELETE FROM clicks WHERE link_id IN (SELECT id FROM links WHERE disabled_at < $1 OR swept_at < $1)`
+	result, err := store.db.Exec(deleteStmt, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// InsertQuiz() Inserts a quiz and its answer into the database
+func (store *sqlStore) InsertQuiz(quiz Quiz) error {
+	choices, err := json.Marshal(quiz.Choices)
+	if err != nil {
+		return err
+	}
+	insertStmt := `INSERT INTO quizzes (id, format, link, choices) VALUES ($1, $2, $3, $4)`
+	_, err = store.db.Exec(insertStmt, quiz.ID, string(quiz.Format), quiz.Link, choices)
+	return err
+}
+
+// GetQuiz() Retrieves a quiz and its answer from the database with a matching id
+func (store *sqlStore) GetQuiz(id string) (Quiz, error) {
+	getStmt := `SELECT id, format, link, choices FROM quizzes WHERE id = ($1) LIMIT 1`
+	var quiz Quiz
+	var choices []byte
+	err := store.db.QueryRow(getStmt, id).Scan(&quiz.ID, &quiz.Format, &quiz.Link, &choices)
+	if errors.Is(err, sql.ErrNoRows) {
+		return quiz, ErrQuizNotFound
+	}
+	if err != nil {
+		return quiz, err
+	}
+	if err := json.Unmarshal(choices, &quiz.Choices); err != nil {
+		return quiz, err
+	}
+	return quiz, nil
+}
+
+// MarkQuizAnswered() records when a quiz was first answered, returning false if it was already answered
+func (store *sqlStore) MarkQuizAnswered(id string) (bool, error) {
+	updateStmt := `UPDATE quizzes SET answered_at = CURRENT_TIMESTAMP WHERE id = ($1) AND answered_at IS NULL`
+	result, err := store.db.Exec(updateStmt, id)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+// InsertSharedLesson() Inserts a saved educational result into the database
+func (store *sqlStore) InsertSharedLesson(lesson SharedLesson) error {
+	insertStmt := `INSERT INTO shared_lessons (id, link, fake_link, technique, explanation, model, prompt_version, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := store.db.Exec(insertStmt, lesson.ID, lesson.Link, lesson.FakeLink, lesson.Technique, lesson.Explanation, lesson.Model,
+		lesson.PromptVersion, lesson.CreatedAt)
+	return err
+}
+
+// GetSharedLesson() Retrieves a saved educational result from the database with a matching id
+func (store *sqlStore) GetSharedLesson(id string) (SharedLesson, error) {
+	getStmt := `SELECT id, link, fake_link, technique, explanation, model, prompt_version, created_at FROM shared_lessons WHERE id = ($1)`
+	var lesson SharedLesson
+	err := store.db.QueryRow(getStmt, id).Scan(&lesson.ID, &lesson.Link, &lesson.FakeLink, &lesson.Technique, &lesson.Explanation,
+		&lesson.Model, &lesson.PromptVersion, &lesson.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SharedLesson{}, ErrLessonNotFound
+	}
 	if err != nil {
-		return "", err
+		return SharedLesson{}, err
 	}
-	return link, nil
+	return lesson, nil
 }
@@ -3,159 +3,93 @@ package link
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"math/rand/v2"
-	"net/http"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/generator"
+	"github.com/JBK2116/phakelinks/internal/learner"
+	"github.com/JBK2116/phakelinks/internal/llm"
+	"github.com/JBK2116/phakelinks/internal/qr"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/internal/validator"
+	"github.com/JBK2116/phakelinks/internal/verifier"
 	"github.com/JBK2116/phakelinks/types"
-	"github.com/openai/openai-go/v3"
-	"github.com/openai/openai-go/v3/option"
-	"github.com/openai/openai-go/v3/responses"
 )
 
-// ValidateCreateLinkDTO() ensures that the provided CreateLinkDTO holds valid information in all fields
-func ValidateCreateLinkDTO(dto types.CreateLinkDTO) *types.ErrorResponse {
+// ValidateCreateLinkDTO() ensures that the provided CreateLinkDTO holds valid information in all fields.
+// Every invalid field is reported, not only the first one.
+func ValidateCreateLinkDTO(v validator.Validator, dto types.CreateLinkDTO) *types.ErrorResponse {
+	var result types.ValidationResult
 	if dto.Link == "" {
-		return &types.ErrorResponse{Error: "MISSING_URL", Message: "A URL is required to create a link."}
+		result.Add("link", "MISSING_URL", "A URL is required to create a link.", "")
+	} else if err := ValidateLink(v, dto.Link); err != nil {
+		result.Add("link", "INVALID_URL", "The URL or domain is not valid. Ensure it includes a scheme (e.g. https://) and a proper domain.", err.Error())
 	}
 	if dto.Mode == "" {
-		return &types.ErrorResponse{
-			Error:   "MISSING_MODE",
-			Message: "A mode must be selected.",
-		}
+		result.Add("mode", "MISSING_MODE", "A mode must be selected.", "")
+	} else if !ValidateMode(dto.Mode) {
+		result.Add("mode", "INVALID_MODE", "The provided mode is not valid", dto.Mode)
 	}
-	if dto.Exclude == nil {
-		return &types.ErrorResponse{
-			Error:   "MISSING_EXCLUDE",
-			Message: "An exclude list is required. Pass an empty array if you have no exclusions.",
-		}
+	if dto.Mode == string(types.Quiz) && !ValidateQuizFormat(dto.QuizFormat) {
+		result.Add("quiz_format", "INVALID_QUIZ_FORMAT", fmt.Sprintf("The quiz format must be %s or %s", types.PickReal, types.RealOrFake), dto.QuizFormat)
 	}
-	if err := ValidateLink(dto.Link); err != nil {
-		return &types.ErrorResponse{
-			Error:   "INVALID_URL",
-			Message: "The URL or domain is not valid. Ensure it includes a scheme (e.g. https://) and a proper domain.",
-			Value:   err.Error(),
-		}
+	result.Errors = append(result.Errors, ValidateExcludes(dto.Exclude)...)
+	result.Errors = append(result.Errors, ValidateLifetime(dto.ActiveFrom, dto.ExpiresAt, dto.MaxClicks, time.Now())...)
+	if dto.RedirectStatus != 0 {
+		result.Errors = append(result.Errors, ValidateRedirectStatus(dto.RedirectStatus)...)
 	}
-	if !ValidateMode(dto.Mode) {
-		return &types.ErrorResponse{
-			Error:   "INVALID_MODE",
-			Message: "The provided mode is not valid",
-			Value:   dto.Mode,
-		}
+	result.Errors = append(result.Errors, ValidateCard(dto.CardTitle, dto.CardImage)...)
+	if dto.Mode == string(types.Prank) && dto.Slug != "" {
+		result.Errors = append(result.Errors, ValidateVanitySlug(dto.Slug)...)
 	}
-	if err := ValidateExcludes(dto.Exclude); err != nil {
-		return &types.ErrorResponse{
-			Error:   "INVALID_EXCLUDE",
-			Message: "One or more exclude patterns are invalid. Ensure all patterns are valid URL paths or glob expressions.",
-			Extra:   err.Error(),
+	if dto.QR != nil {
+		_, errs := qr.NewOptions(dto.QR.Format, dto.QR.Level, dto.QR.Size)
+		for _, err := range errs {
+			err.Field = "qr." + err.Field
+			result.Errors = append(result.Errors, err)
 		}
 	}
-	return nil
-}
-
-// ValidateLink() ensures that the provided CreateLinkDTO holds valid url information
-func ValidateLink(link string) error {
-	httpClient := &http.Client{}
-	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
-		return ValidateURL(link, *httpClient)
-	} else {
-		return ValidateDomain(link, *httpClient)
-	}
-}
-
-// ValidateURL() checks that the provided URL string is a valid, well-formed HTTP/HTTPS URL.
-func ValidateURL(rawURL string, client http.Client) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
-	defer cancel()
-	url := "https://api.cloudmersive.com/validate/domain/url/full"
-	method := "POST"
-	body, err := json.Marshal(map[string]string{"URL": rawURL})
-	if err != nil {
-		return err
-	}
-	payload := strings.NewReader(string(body))
-	req, err := http.NewRequestWithContext(ctx, method, url, payload)
-	if err != nil {
-		return err
-	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("ApiKey", configs.Envs.CLOUDMERSIVE_KEY)
-	res, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-	resBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-	result := make(map[string]any)
-	if err := json.Unmarshal(resBody, &result); err != nil {
-		return err
-	}
-	if valid, ok := result["ValidURL"].(bool); !ok || !valid {
-		return fmt.Errorf("%s", rawURL)
-	}
-	return nil
+	return result.ErrorResponse()
 }
 
-// ValidateDomain() checks the the provided domain string is a valid, well-formed web domain
-func ValidateDomain(rawDomain string, client http.Client) error {
+// ValidateLink() ensures that the canonical form of the provided link or domain is a valid URL
+func ValidateLink(v validator.Validator, link string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	url := "https://api.cloudmersive.com/validate/domain/check"
-	method := "POST"
-	payload := strings.NewReader(rawDomain)
-	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	canonicalLink, err := validator.Canonicalize(link)
 	if err != nil {
 		return err
 	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("ApiKey", configs.Envs.CLOUDMERSIVE_KEY)
-	res, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-	result := make(map[string]bool)
-	if err := json.Unmarshal(body, &result); err != nil {
-		return err
-	}
-	if !result["ValidDomain"] {
-		return fmt.Errorf("%s", rawDomain)
-	}
-	return nil
+	return v.ValidateURL(ctx, canonicalLink)
 }
 
 // ValidateMode() checks that the provided mode is a valid mode defined in `types.go`
 func ValidateMode(mode string) bool {
-	return mode == string(types.Educational) || mode == string(types.Prank)
+	return mode == string(types.Educational) || mode == string(types.Prank) || mode == string(types.Quiz)
 }
 
-func ValidateExcludes(exclude []string) error {
-	if len(exclude) >= len(types.AllPhishingTechniques) {
-		return fmt.Errorf("Length of exclude array must be less than %d", len(types.AllPhishingTechniques))
+// ValidateExcludes() checks that every exclude is a technique in the catalog and at least one technique remains
+func ValidateExcludes(exclude []string) []types.FieldError {
+	var result types.ValidationResult
+	if len(exclude) >= technique.Count() {
+		result.Add("exclude", "INVALID_EXCLUDE", fmt.Sprintf("Length of exclude array must be less than %d", technique.Count()), fmt.Sprint(len(exclude)))
 	}
 	seen := make(map[string]struct{})
-	for _, v := range exclude {
+	for i, v := range exclude {
 		if _, exists := seen[v]; exists {
 			continue
 		}
-		if !contains(types.AllPhishingTechniques, v) {
-			return fmt.Errorf("%s is an invalid exclude type", v)
+		if _, ok := technique.Lookup(v); !ok {
+			result.Add(fmt.Sprintf("exclude[%d]", i), "INVALID_EXCLUDE", fmt.Sprintf("%s is an invalid exclude type", v), v)
 		}
 		seen[v] = struct{}{}
 	}
-	return nil
+	return result.Errors
 }
 
 // contains() checks if the provided string is in the provided array slice
@@ -168,98 +102,281 @@ func contains[T ~string](slice []T, s string) bool {
 	return false
 }
 
-// GetRandomPhishingTechnique() returns a random PhishingTechnique enum
-func GetRandomPhishingTechnique(excludes []string) string {
-	availableTechniques := make([]string, 0)
-	for _, v := range types.AllPhishingTechniques {
-		if !contains(excludes, string(v)) {
-			availableTechniques = append(availableTechniques, string(v))
+// ErrNoTechnique is returned when every technique is excluded
+var ErrNoTechnique = errors.New("every phishing technique is excluded")
+
+// GetRandomPhishingTechnique() returns a random PhishingTechnique enum that favours the techniques the learner has seen least or keeps missing
+func GetRandomPhishingTechnique(excludes []string, history []types.MasteryDTO) (string, error) {
+	phishingTech, ok := learner.NextTechnique(history, excludes)
+	if !ok {
+		return "", ErrNoTechnique
+	}
+	return string(phishingTech), nil
+}
+
+// const here stores all values accepted by `configs.Envs.GeneratorMode`
+const (
+	GeneratorLLM     = "llm"
+	GeneratorOffline = "offline"
+	GeneratorHybrid  = "hybrid"
+)
+
+// PickEducationalSummary() returns the educational summary of a technique picked for the learner along with the model that wrote it.
+// Techniques the offline generator cannot apply to the link (e.g. a hyphen in a one letter brand) are excluded and another one is picked.
+func PickEducationalSummary(ctx context.Context, provider llm.Provider, url string, excludes []string, history []types.MasteryDTO) (types.ExplanationDTO, string, error) {
+	excludes = slices.Clone(excludes)
+	remaining := 0
+	for _, id := range technique.IDs() {
+		if !contains(excludes, string(id)) {
+			remaining++
+		}
+	}
+	for {
+		phishingTech, err := GetRandomPhishingTechnique(excludes, history)
+		if err != nil {
+			return types.ExplanationDTO{}, "", err
+		}
+		dto, model, err := GetEducationalSummary(ctx, provider, phishingTech, url)
+		remaining--
+		if !errors.Is(err, generator.ErrNoVariant) || remaining == 0 {
+			return dto, model, err
+		}
+		excludes = append(excludes, phishingTech)
+	}
+}
+
+// OfflineModel is recorded as the model of summaries written by the offline generator
+const OfflineModel = "offline"
+
+// PromptVersion identifies the wording of `GetAIPrompt()` and is saved with every shared lesson, bump it whenever the prompt changes
+const PromptVersion = "1"
+
+// GetEducationalSummary() returns the `ExplanationDTO` from the generator selected by `configs.Envs.GeneratorMode`
+// along with the model that wrote it, which is `OfflineModel` when the offline generator did.
+func GetEducationalSummary(ctx context.Context, provider llm.Provider, phishingTech string, url string) (types.ExplanationDTO, string, error) {
+	switch configs.Envs.GeneratorMode {
+	case GeneratorOffline:
+		dto, err := GetOfflineSummary(phishingTech, url)
+		return dto, OfflineModel, err
+	case GeneratorHybrid:
+		dto, err := GetEducationalAISummary(ctx, provider, phishingTech, url)
+		if err != nil {
+			dto, err = GetOfflineSummary(phishingTech, url)
+			return dto, OfflineModel, err
 		}
+		return dto, provider.Model(), nil
+	default:
+		dto, err := GetEducationalAISummary(ctx, provider, phishingTech, url)
+		return dto, provider.Model(), err
 	}
-	randIndex := rand.IntN(len(availableTechniques))
-	return availableTechniques[randIndex]
 }
 
-// GetEducationalAISummary() queries the OPENAI API for the AI summary, returning the `ExplanationDTO` if successful
-func GetEducationalAISummary(phishingTech string, url string) (types.ExplanationDTO, error) {
+// GetOfflineSummary() returns the verified `ExplanationDTO` built by the offline rule-based generator
+func GetOfflineSummary(phishingTech string, url string) (types.ExplanationDTO, error) {
+	dto, err := generator.Generate(types.PhishingTechnique(phishingTech), url)
+	if err != nil {
+		return dto, err
+	}
+	if err := verifier.Verify(types.PhishingTechnique(phishingTech), url, dto.FakeLink); err != nil {
+		return dto, err
+	}
+	return dto, nil
+}
+
+// maxSummaryAttempts is the number of times the LLM provider is queried before giving up on a summary
+const maxSummaryAttempts = 3
+
+// explanationSchema constrains the LLM output to the shape of `types.ExplanationDTO`
+var explanationSchema = &llm.Schema{
+	Name: "educational_summary",
+	Definition: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"fake_link": map[string]any{
+				"type":        "string",
+				"description": "A realistic phishing URL that uses the requested technique",
+			},
+			"explanation": map[string]any{
+				"type":        "string",
+				"description": "A 4-6 sentence explanation of the technique, why it works and how to spot it",
+			},
+		},
+		"required":             []string{"fake_link", "explanation"},
+		"additionalProperties": false,
+	},
+}
+
+// sentenceEnd matches the punctuation that terminates a sentence
+var sentenceEnd = regexp.MustCompile(`[.!?]+(\s|$)`)
+
+// UpstreamError represents a failure of the LLM provider to return a usable response
+type UpstreamError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("upstream model failed after %d attempt(s): %s", e.Attempts, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// GetEducationalAISummary() queries the LLM provider for the AI summary, returning the `ExplanationDTO` if successful.
+// Output that is malformed or fails technique verification is fed back to the model and retried up to `maxSummaryAttempts` times before an `UpstreamError` is returned.
+// Every attempt shares a one minute deadline that never outlives the provided context.
+func GetEducationalAISummary(ctx context.Context, provider llm.Provider, phishingTech string, url string) (types.ExplanationDTO, error) {
 	duration := time.Minute * 1
-	ctx, cancelCtx := context.WithTimeout(context.Background(), duration)
+	ctx, cancelCtx := context.WithTimeout(ctx, duration)
 	defer cancelCtx()
 
-	client := openai.NewClient(
-		option.WithAPIKey(configs.Envs.OPENAI_KEY),
-	)
 	question := GetAIPrompt(phishingTech, url)
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Input: responses.ResponseNewParamsInputUnion{OfString: openai.String(question)},
-		Model: openai.ChatModelGPT4o,
-	})
+	request := llm.UserRequest(question)
+	request.Schema = explanationSchema
 	var dto types.ExplanationDTO
-	if err != nil {
-		return dto, err
+	var lastErr error
+	for attempt := 1; attempt <= maxSummaryAttempts; attempt++ {
+		output, err := provider.Complete(ctx, request)
+		if err != nil {
+			return dto, &UpstreamError{Attempts: attempt, Err: err}
+		}
+		dto, lastErr = ParseExplanation(output)
+		if lastErr == nil {
+			lastErr = verifier.Verify(types.PhishingTechnique(phishingTech), url, dto.FakeLink)
+		}
+		if lastErr == nil {
+			dto.Technique = phishingTech
+			return dto, nil
+		}
+		request.Messages = append(request.Messages,
+			llm.Message{Role: llm.RoleAssistant, Content: output},
+			llm.Message{Role: llm.RoleUser, Content: GetRetryPrompt(lastErr)},
+		)
 	}
-	cleaned := strings.TrimSpace(response.OutputText())
+	return dto, &UpstreamError{Attempts: maxSummaryAttempts, Err: lastErr}
+}
+
+// ParseExplanation() strictly decodes the raw model output into an `ExplanationDTO` and validates every field
+func ParseExplanation(output string) (types.ExplanationDTO, error) {
+	var dto types.ExplanationDTO
+	cleaned := strings.TrimSpace(output)
 	cleaned = strings.TrimPrefix(cleaned, "```json")
 	cleaned = strings.TrimPrefix(cleaned, "```")
 	cleaned = strings.TrimSuffix(cleaned, "```")
 	cleaned = strings.TrimSpace(cleaned)
 
-	if err := json.Unmarshal([]byte(cleaned), &dto); err != nil {
-		return dto, err
+	decoder := json.NewDecoder(strings.NewReader(cleaned))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&dto); err != nil {
+		return dto, fmt.Errorf("response is not a valid JSON object: %w", err)
 	}
-	dto.Technique = phishingTech
-	return dto, nil
+	if decoder.More() {
+		return dto, fmt.Errorf("response contains data after the JSON object")
+	}
+	return dto, ValidateExplanation(dto)
 }
 
-func GetPrankLink(url string) (types.PrankDTO, error) {
+// ValidateExplanation() ensures that the provided `ExplanationDTO` holds a fake link and a 4-6 sentence explanation
+func ValidateExplanation(dto types.ExplanationDTO) error {
+	if strings.TrimSpace(dto.FakeLink) == "" {
+		return fmt.Errorf("fake_link must not be empty")
+	}
+	if strings.ContainsAny(strings.TrimSpace(dto.FakeLink), " \t\n") {
+		return fmt.Errorf("fake_link must not contain whitespace")
+	}
+	if count := CountSentences(dto.Explanation); count < 4 || count > 6 {
+		return fmt.Errorf("explanation must contain 4-6 sentences, found %d", count)
+	}
+	return nil
+}
+
+// CountSentences() returns the number of sentences in the provided text
+func CountSentences(text string) int {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0
+	}
+	matches := sentenceEnd.FindAllStringIndex(text, -1)
+	count := len(matches)
+	if count == 0 || matches[count-1][1] < len(text) {
+		count++
+	}
+	return count
+}
+
+// GetPrankLink() queries the LLM provider for a suspicious looking slug, returning the `PrankDTO` if successful
+func GetPrankLink(provider llm.Provider, url string) (types.PrankDTO, error) {
 	duration := time.Minute * 1
 	ctx, cancelCtx := context.WithTimeout(context.Background(), duration)
 	defer cancelCtx()
-	client := openai.NewClient(option.WithAPIKey(configs.Envs.OPENAI_KEY))
 	question := GetPrankPrompt(url)
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Input: responses.ResponseNewParamsInputUnion{OfString: openai.String(question)},
-		Model: openai.ChatModelGPT4o,
-	})
+	output, err := provider.Complete(ctx, llm.UserRequest(question))
 	var dto types.PrankDTO
 	if err != nil {
 		return dto, err
 	}
+	dto.Slug = SanitizeSlug(output)
+	dto.Link = PrankURL(dto.Slug)
+	return dto, nil
+}
+
+// PrankURL() returns the full prank link served by the redirect server for the provided slug
+func PrankURL(slug string) string {
 	if configs.Envs.IsDev {
-		dto.Link = fmt.Sprintf("%s:%s/%s", configs.Envs.RedirectHost, configs.Envs.RedirectPort, response.OutputText())
-	} else {
-		dto.Link = fmt.Sprintf("%s/%s", configs.Envs.RedirectHost, response.OutputText())
+		return fmt.Sprintf("%s:%s/%s", configs.Envs.RedirectHost, configs.Envs.RedirectPort, slug)
 	}
-	dto.Slug = strings.TrimSpace(response.OutputText())
-	return dto, nil
+	return fmt.Sprintf("%s/%s", configs.Envs.RedirectHost, slug)
 }
 
+// NewQRCode() returns the QR code of the provided link rendered with the provided options
+func NewQRCode(link string, dto types.QROptionsDTO) (*types.QRCodeDTO, error) {
+	options, errs := qr.NewOptions(dto.Format, dto.Level, dto.Size)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid QR code options: %s", errs[0].Message)
+	}
+	dataURI, err := qr.DataURI(link, options)
+	if err != nil {
+		return nil, err
+	}
+	return &types.QRCodeDTO{Content: link, Format: options.Format, Level: options.Level, Size: options.Size, DataURI: dataURI}, nil
+}
+
+// Destination() returns the URL the link redirects to
+func (record Record) Destination() string {
+	// links stored before canonicalization may not include a scheme
+	if !strings.Contains(record.Link, "://") {
+		return fmt.Sprintf("https://%s", record.Link)
+	}
+	return record.Link
+}
+
+// GetAIPrompt() returns a string representing an AI prompt that generates an educational fake link and explanation
 func GetAIPrompt(phishingTech string, url string) string {
-	technique := string(phishingTech)
 	return fmt.Sprintf(`You are a cybersecurity expert and phishing URL generator.
 Given the legitimate URL "%s" and the phishing technique "%s", return a JSON object with exactly two fields:
 1. "fake_link": A realistic, convincing phishing URL using the specified technique. It must look legitimate enough to fool a non-technical user. Do not make it obviously fake. Preserve the path and query params from the original URL where possible.
 2. "explanation": A 4-6 sentence explanation covering: what technique is used, why it's effective, and how to spot it.
 Technique definitions:
-- character-substitution: Swap a character for a visually similar one (e.g. amazon.com -> arnazon.com, 0 for o)
-- homoglyphs: Replace letters with visually identical Unicode chars from other scripts (e.g. rn -> m lookalike)
-- idn-homograph: Use internationalized domain name Unicode chars that render identically in browsers (e.g. Cyrillic a vs Latin a)
-- dot-manipulation: Add, remove, or move dots in the domain (e.g. amazon.com -> amaz.on.com)
-- hyphen-insertion: Insert hyphens to break up the real domain (e.g. amazon.com -> amazon-login.com)
-- top-level-domain-swap: Change the TLD to something believable (e.g. amazon.com -> amazon.co, amazon.net)
-- subdomain-abuse: Make the real domain a subdomain of a fake one (e.g. amazon.com -> amazon.verify-login.com)
-- combo-squatting: Append a legitimate-sounding word (e.g. amazon.com -> amazon-secure.com)
-- typosquatting: Use common keyboard typos of the domain (e.g. amazon.com -> amazom.com, gogle.com)
-- punycode: Use xn-- encoded internationalized domain that renders identically in browsers (e.g. xn--mazon-wqa.com appearing as amazon.com)
-- path-manipulation: Embed the real domain in the URL path of a fake one (e.g. evil.com/www.amazon.com/login)
-- open-redirect: Abuse a legitimate sites redirect parameter to forward to a malicious site (e.g. google.com/url?q=evil.com)
-- at-symbol-abuse: Use the @ symbol so the browser ignores everything before it (e.g. https://amazon.com@evil.com)
-- port-abuse: Append a port that looks like part of a legitimate domain (e.g. amazon.com:8080.evil.com)
-- https-deception: Place https or a trusted brand in the subdomain to appear secure (e.g. https.amazon.com.evil.com)
-- lookalike-domain: Register a domain visually similar to the real one (e.g. arnazon.com, paypa1.com)
+%s
 IMPORTANT: The fake link must be subtle and convincing enough that a real person could genuinely fall for it. It should not look obviously fake or suspicious. The goal is realism — this is a cybersecurity education tool and the more realistic the example, the more valuable the lesson.
 You must return a raw JSON object. Do not use markdown. Do not use code fences. Do not wrap in backticks. The very first character of your response must be { and the very last character must be }.
-{"fake_link": "...", "explanation": "..."}`, url, technique)
+{"fake_link": "...", "explanation": "..."}`, url, phishingTech, GetTechniqueDefinitions())
+}
+
+// GetTechniqueDefinitions() returns one prompt line per technique in the catalog
+func GetTechniqueDefinitions() string {
+	lines := make([]string, 0, technique.Count())
+	for _, entry := range technique.All() {
+		lines = append(lines, fmt.Sprintf("- %s: %s", entry.ID, entry.Summary))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GetRetryPrompt() returns a string representing an AI prompt asking the model to correct its previous response
+func GetRetryPrompt(err error) string {
+	return fmt.Sprintf(`Your previous response was rejected: %s.
+Return a corrected raw JSON object with exactly the fields "fake_link" and "explanation". The fake_link must really use the requested technique and the explanation must contain 4-6 sentences. Do not use markdown or code fences.`, err)
 }
 
 // GetPrankPrompt() returns a string representing an AI prompt that returns a sketchy looking link
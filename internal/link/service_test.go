@@ -0,0 +1,158 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/JBK2116/phakelinks/internal/llm"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// explanation holds an explanation of the accepted length
+const explanation = "The top-level domain was swapped. The brand looks untouched so the link feels familiar. Attackers register the same name under another ending. Always check the ending of the domain."
+
+func TestGetEducationalAISummary(t *testing.T) {
+	valid := `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `"}`
+	tests := []struct {
+		name      string
+		responses []llm.StubResponse
+		// wantRequests is the number of requests sent to the provider, wantAttempts the attempts of the UpstreamError if one is expected
+		wantRequests int
+		wantAttempts int
+		// wantFeedback holds a fragment of the retry prompt sent after the first response
+		wantFeedback string
+	}{
+		{
+			name:         "valid on the first try",
+			responses:    []llm.StubResponse{{Text: valid}},
+			wantRequests: 1,
+		},
+		{
+			name:         "valid inside a code fence",
+			responses:    []llm.StubResponse{{Text: "```json\n" + valid + "\n```"}},
+			wantRequests: 1,
+		},
+		{
+			name:         "malformed then valid",
+			responses:    []llm.StubResponse{{Text: `{"fake_link": "https://paypal.net", "explanation": "Too short."}`}, {Text: valid}},
+			wantRequests: 2,
+			wantFeedback: "explanation must contain 4-6 sentences, found 1",
+		},
+		{
+			name:         "wrong technique then valid",
+			responses:    []llm.StubResponse{{Text: `{"fake_link": "https://paypa1.com", "explanation": "` + explanation + `"}`}, {Text: valid}},
+			wantRequests: 2,
+			wantFeedback: "does not use the requested technique",
+		},
+		{
+			name: "three invalid responses",
+			responses: []llm.StubResponse{
+				{Text: "not json"},
+				{Text: `{"fake_link": "", "explanation": "` + explanation + `"}`},
+				{Text: `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `", "notes": "extra"}`},
+				{Text: valid},
+			},
+			wantRequests: 3,
+			wantAttempts: 3,
+		},
+		{
+			name:         "provider error",
+			responses:    []llm.StubResponse{{Err: errors.New("rate limited")}, {Text: valid}},
+			wantRequests: 1,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := llm.NewStubProvider(tt.responses...)
+			dto, err := GetEducationalAISummary(context.Background(), provider, string(types.TLDSwap), "https://paypal.com")
+			requests := provider.Requests()
+			if len(requests) != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", len(requests), tt.wantRequests)
+			}
+			if tt.wantAttempts > 0 {
+				var upstreamErr *UpstreamError
+				if !errors.As(err, &upstreamErr) {
+					t.Fatalf("GetEducationalAISummary() error = %v, want an UpstreamError", err)
+				}
+				if upstreamErr.Attempts != tt.wantAttempts {
+					t.Errorf("UpstreamError.Attempts = %d, want %d", upstreamErr.Attempts, tt.wantAttempts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEducationalAISummary() error = %v", err)
+			}
+			if dto.FakeLink != "https://paypal.net" || dto.Technique != string(types.TLDSwap) {
+				t.Errorf("GetEducationalAISummary() = %+v", dto)
+			}
+			for _, request := range requests {
+				if request.Schema == nil {
+					t.Errorf("request has no schema")
+				}
+			}
+			if tt.wantFeedback == "" {
+				return
+			}
+			retry := requests[1].Messages
+			if len(retry) != 3 || retry[1].Role != llm.RoleAssistant || retry[2].Role != llm.RoleUser {
+				t.Fatalf("retry messages = %+v, want the question, the rejected answer and the feedback", retry)
+			}
+			if retry[1].Content != tt.responses[0].Text {
+				t.Errorf("retry repeats %q, want the rejected answer %q", retry[1].Content, tt.responses[0].Text)
+			}
+			if !strings.Contains(retry[2].Content, tt.wantFeedback) {
+				t.Errorf("retry feedback %q does not mention %q", retry[2].Content, tt.wantFeedback)
+			}
+		})
+	}
+}
+
+func TestParseExplanation(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{name: "valid", output: `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `"}`},
+		{name: "code fence", output: "```\n" + `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `"}` + "\n```"},
+		{name: "unknown field", output: `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `", "notes": "extra"}`, wantErr: `unknown field "notes"`},
+		{name: "data after the object", output: `{"fake_link": "https://paypal.net", "explanation": "` + explanation + `"} {}`, wantErr: "data after the JSON object"},
+		{name: "not json", output: "https://paypal.net", wantErr: "not a valid JSON object"},
+		{name: "empty fake link", output: `{"fake_link": " ", "explanation": "` + explanation + `"}`, wantErr: "fake_link must not be empty"},
+		{name: "whitespace in fake link", output: `{"fake_link": "https://pay pal.net", "explanation": "` + explanation + `"}`, wantErr: "fake_link must not contain whitespace"},
+		{name: "too few sentences", output: `{"fake_link": "https://paypal.net", "explanation": "One. Two. Three."}`, wantErr: "found 3"},
+		{name: "too many sentences", output: `{"fake_link": "https://paypal.net", "explanation": "One. Two. Three. Four. Five. Six. Seven."}`, wantErr: "found 7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExplanation(tt.output)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParseExplanation() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseExplanation() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetRandomPhishingTechnique(t *testing.T) {
+	var all []string
+	for _, id := range technique.IDs() {
+		all = append(all, string(id))
+	}
+	if _, err := GetRandomPhishingTechnique(all, nil); !errors.Is(err, ErrNoTechnique) {
+		t.Errorf("GetRandomPhishingTechnique() with every technique excluded error = %v, want %v", err, ErrNoTechnique)
+	}
+	got, err := GetRandomPhishingTechnique(all[1:], nil)
+	if err != nil || got != all[0] {
+		t.Errorf("GetRandomPhishingTechnique() = %q, %v, want %q", got, err, all[0])
+	}
+}
@@ -0,0 +1,91 @@
+package link
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/internal/technique"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+// sharedLessonTemplate renders the public page of a saved educational result
+var sharedLessonTemplate = mustParseTemplate("templates/shared_lesson.html")
+
+// SharedLesson represents a saved educational result, it never expires so trainers can keep sharing it
+type SharedLesson struct {
+	ID            string
+	Link          string
+	FakeLink      string
+	Technique     string
+	Explanation   string
+	Model         string
+	PromptVersion string
+	CreatedAt     time.Time
+}
+
+// SharedLessonPage represents the data rendered on the page of a shared lesson
+type SharedLessonPage struct {
+	SharedLesson
+	TechniqueName string
+	Created       string
+	Frontend      string
+}
+
+// NewSharedLesson() returns the lesson of the provided educational result written by the provided model.
+// Only summaries written by a model record the prompt version, the offline generator has no prompt.
+func NewSharedLesson(link string, dto types.ExplanationDTO, model string) SharedLesson {
+	lesson := SharedLesson{
+		ID:          rand.Text(),
+		Link:        link,
+		FakeLink:    dto.FakeLink,
+		Technique:   dto.Technique,
+		Explanation: dto.Explanation,
+		Model:       model,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if model != OfflineModel {
+		lesson.PromptVersion = PromptVersion
+	}
+	return lesson
+}
+
+// LessonURL() returns the public address of the shared lesson with the provided id
+func LessonURL(id string) string {
+	return PrankURL("lessons/" + id)
+}
+
+// DTO() returns the shared lesson as returned by the API
+func (lesson SharedLesson) DTO() types.SharedLessonDTO {
+	return types.SharedLessonDTO{
+		ID:            lesson.ID,
+		URL:           LessonURL(lesson.ID),
+		Link:          lesson.Link,
+		FakeLink:      lesson.FakeLink,
+		Technique:     lesson.Technique,
+		Explanation:   lesson.Explanation,
+		Model:         lesson.Model,
+		PromptVersion: lesson.PromptVersion,
+		CreatedAt:     lesson.CreatedAt.UTC(),
+	}
+}
+
+// NewSharedLessonPage() returns the page of the provided shared lesson
+func NewSharedLessonPage(lesson SharedLesson) SharedLessonPage {
+	page := SharedLessonPage{
+		SharedLesson:  lesson,
+		TechniqueName: lesson.Technique,
+		Created:       lesson.CreatedAt.UTC().Format("January 2, 2006 at 15:04 UTC"),
+		Frontend:      configs.Envs.FrontendHost,
+	}
+	if entry, ok := technique.Lookup(lesson.Technique); ok {
+		page.TechniqueName = entry.Name
+	}
+	return page
+}
+
+// RenderSharedLesson() writes the page of a shared lesson to the provided writer
+func RenderSharedLesson(writer io.Writer, page SharedLessonPage) error {
+	return sharedLessonTemplate.Execute(writer, page)
+}
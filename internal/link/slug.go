@@ -0,0 +1,135 @@
+package link
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JBK2116/phakelinks/types"
+	"golang.org/x/text/unicode/norm"
+)
+
+// const here stores the limits of a slug
+const (
+	minSlugLength    = 3
+	maxSlugLength    = 64
+	slugSuffixLength = 4
+	maxSlugAttempts  = 5
+)
+
+// ErrSlugTaken is returned when the slug of a link already belongs to another link
+var ErrSlugTaken = errors.New("slug is already taken")
+
+// reservedSlugs holds slugs that clash with routes of the redirect server or look like they belong to phakelinks itself
+var reservedSlugs = []string{
+	"preview", "api", "qr", "lessons", "static", "assets", "admin", "login", "logout", "health",
+	"favicon.ico", "robots.txt", "sitemap.xml", "phakelinks",
+}
+
+// profaneWords holds words that are never accepted inside a slug
+var profaneWords = []string{
+	"anal", "anus", "arse", "asshole", "bastard", "bitch", "bollocks", "boner", "boob", "cock", "crap", "cunt", "dick",
+	"dildo", "fag", "faggot", "fuck", "jizz", "kike", "nazi", "nigga", "nigger", "penis", "piss", "porn", "pussy",
+	"rape", "retard", "shit", "slut", "spic", "tits", "twat", "vagina", "wank", "whore",
+}
+
+// SanitizeSlug() returns the URL-safe form of the provided slug.
+// Letters are lowercased and stripped of accents, every run of anything that is not a letter, digit, `.` or `_` becomes a single `-`,
+// and the result is trimmed to `maxSlugLength` runes.
+func SanitizeSlug(raw string) string {
+	var builder strings.Builder
+	pendingDash := false
+	// accents are split from their letters so `ü` becomes `u` instead of a separator
+	for _, r := range norm.NFKD.String(strings.ToLower(strings.TrimSpace(raw))) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_':
+			if pendingDash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			pendingDash = false
+			builder.WriteRune(r)
+		default:
+			pendingDash = true
+		}
+	}
+	slug := builder.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	return strings.Trim(slug, "-._")
+}
+
+// ValidateVanitySlug() checks that the slug requested by the creator of a link is URL-safe, not reserved and not profane
+func ValidateVanitySlug(slug string) []types.FieldError {
+	var result types.ValidationResult
+	length := utf8.RuneCountInString(slug)
+	switch {
+	case SanitizeSlug(slug) != slug:
+		result.Add("slug", "INVALID_SLUG", "The slug may only contain lowercase letters, digits, '.', '_' and '-', and must start and end with a letter or digit.", slug)
+	case length < minSlugLength || length > maxSlugLength:
+		result.Add("slug", "INVALID_SLUG", fmt.Sprintf("The slug must be between %d and %d characters.", minSlugLength, maxSlugLength), slug)
+	case IsReservedSlug(slug):
+		result.Add("slug", "RESERVED_SLUG", "The slug is reserved, please pick another one.", slug)
+	case IsProfaneSlug(slug):
+		result.Add("slug", "PROFANE_SLUG", "The slug contains a word that is not allowed, please pick another one.", slug)
+	}
+	return result.Errors
+}
+
+// IsReservedSlug() checks if the provided sanitized slug is reserved
+func IsReservedSlug(slug string) bool {
+	return slices.Contains(reservedSlugs, slug)
+}
+
+// IsProfaneSlug() checks if any word of the provided sanitized slug is profane.
+// Words are matched on their own and with every separator removed so `f-u-c-k` is caught, but `scunthorpe` is not.
+func IsProfaneSlug(slug string) bool {
+	words := strings.FieldsFunc(slug, func(r rune) bool { return r == '-' || r == '.' || r == '_' })
+	for _, word := range append(words, strings.Join(words, "")) {
+		word = strings.TrimRight(word, "0123456789")
+		if slices.Contains(profaneWords, word) || slices.Contains(profaneWords, strings.TrimSuffix(word, "s")) || slices.Contains(profaneWords, strings.TrimSuffix(word, "es")) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllocateLink() inserts the link under the provided sanitized slug.
+// A generated slug that is taken, reserved or empty is retried with a random suffix, a vanity slug is never changed.
+// The store enforces unique slugs, so the insert itself is the check and concurrent requests cannot share a slug.
+func AllocateLink(store LinkStore, record Record, slug string, vanity bool) (Record, error) {
+	if vanity {
+		record.FakeLink = slug
+		return record, store.InsertLink(record)
+	}
+	if IsProfaneSlug(slug) {
+		slug = ""
+	}
+	for attempt := range maxSlugAttempts {
+		record.FakeLink = candidateSlug(slug, attempt)
+		err := store.InsertLink(record)
+		if !errors.Is(err, ErrSlugTaken) {
+			return record, err
+		}
+	}
+	return record, ErrSlugTaken
+}
+
+// candidateSlug() returns the slug to try on the provided attempt, every attempt after the first adds a random suffix
+func candidateSlug(slug string, attempt int) string {
+	if attempt == 0 && len(slug) >= minSlugLength && !IsReservedSlug(slug) {
+		return slug
+	}
+	suffix := strings.ToLower(rand.Text()[:slugSuffixLength])
+	if slug == "" {
+		return "link-" + suffix
+	}
+	slug = strings.TrimRight(slug[:min(len(slug), maxSlugLength-slugSuffixLength-1)], "-._")
+	return slug + "-" + suffix
+}
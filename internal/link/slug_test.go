@@ -0,0 +1,84 @@
+package link
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "already clean", raw: "free-gift", want: "free-gift"},
+		{name: "uppercase", raw: "Free-Gift", want: "free-gift"},
+		{name: "spaces", raw: "  free gift card  ", want: "free-gift-card"},
+		{name: "separator runs", raw: "free!!!  gift///card", want: "free-gift-card"},
+		{name: "dots and underscores", raw: "free.gift_card", want: "free.gift_card"},
+		{name: "accents", raw: "Crème Brûlée", want: "creme-brulee"},
+		{name: "compatibility forms", raw: "ｆｒｅｅ①", want: "free1"},
+		{name: "leading and trailing separators", raw: "--._free._--", want: "free"},
+		{name: "non-latin", raw: "подарок", want: ""},
+		{name: "empty", raw: "   ", want: ""},
+		{name: "too long", raw: strings.Repeat("a", maxSlugLength+10), want: strings.Repeat("a", maxSlugLength)},
+		{name: "too long ends in separator", raw: strings.Repeat("a", maxSlugLength-1) + "-b", want: strings.Repeat("a", maxSlugLength-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeSlug(tt.raw); got != tt.want {
+				t.Errorf("SanitizeSlug(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		slug    string
+		vanity  bool
+		pattern string
+		err     error
+	}{
+		{name: "free slug", slug: "free-gift", pattern: `^free-gift$`},
+		{name: "taken slug", slug: "taken", pattern: `^taken-[a-z0-9]{4}$`},
+		{name: "reserved slug", slug: "preview", pattern: `^preview-[a-z0-9]{4}$`},
+		{name: "short slug", slug: "ab", pattern: `^ab-[a-z0-9]{4}$`},
+		{name: "empty slug", slug: "", pattern: `^link-[a-z0-9]{4}$`},
+		{name: "profane slug", slug: "shit-deal", pattern: `^link-[a-z0-9]{4}$`},
+		{name: "free long slug", slug: strings.Repeat("b", maxSlugLength), pattern: `^b{64}$`},
+		{name: "taken long slug", slug: strings.Repeat("a", maxSlugLength), pattern: `^a{59}-[a-z0-9]{4}$`},
+		{name: "free vanity slug", slug: "my-vanity", vanity: true, pattern: `^my-vanity$`},
+		{name: "taken vanity slug", slug: "taken", vanity: true, err: ErrSlugTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			for _, taken := range []string{"taken", strings.Repeat("a", maxSlugLength)} {
+				if err := store.InsertLink(Record{FakeLink: taken, Link: "https://example.com"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			record, err := AllocateLink(store, Record{Link: "https://paypal.com"}, tt.slug, tt.vanity)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("AllocateLink(%q) error = %v, want %v", tt.slug, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AllocateLink(%q) returned error %v", tt.slug, err)
+			}
+			if !regexp.MustCompile(tt.pattern).MatchString(record.FakeLink) {
+				t.Errorf("AllocateLink(%q) slug = %q, want a match for %s", tt.slug, record.FakeLink, tt.pattern)
+			}
+			stored, err := store.GetLink(record.FakeLink)
+			if err != nil || stored.Link != "https://paypal.com" {
+				t.Errorf("GetLink(%q) = %+v, %v, want the allocated link", record.FakeLink, stored, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package link
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/types"
+)
+
+var (
+	ErrLinkNotFound   = errors.New("link not found")
+	ErrQuizNotFound   = errors.New("quiz not found")
+	ErrLessonNotFound = errors.New("lesson not found")
+)
+
+// LinkStore represents where prank links, their destination history, quizzes and shared lessons are stored
+type LinkStore interface {
+	// InsertLink() stores a link and the first version of its destination, it returns ErrSlugTaken if the slug is in use
+	InsertLink(record Record) error
+	// GetLink() returns the link with the provided slug, or ErrLinkNotFound
+	GetLink(slug string) (Record, error)
+	// UpdateLink() changes the settings of a link, recording a new version when its destination changed
+	UpdateLink(record Record, destinationChanged bool) error
+	// GetVersions() returns every destination the link has had, oldest first
+	GetVersions(id int64) ([]types.LinkVersionDTO, error)
+	// DisableLink() marks the link as disabled so it no longer redirects
+	DisableLink(id int64) error
+	// ClaimClick() counts a click against the click limit of the link, returning false if the limit was already reached
+	ClaimClick(id int64) (bool, error)
+	// SweepDeadLinks() marks every expired or exhausted link as swept, it never disables them
+	SweepDeadLinks(now time.Time) (int64, error)
+	// PurgeDeadLinks() deletes the clicks of every link disabled or swept before the provided time, the links themselves are kept
+	PurgeDeadLinks(before time.Time) (int64, error)
+	// InsertQuiz() stores a quiz and its answer
+	InsertQuiz(quiz Quiz) error
+	// GetQuiz() returns the quiz with the provided id, or ErrQuizNotFound
+	GetQuiz(id string) (Quiz, error)
+	// MarkQuizAnswered() records when a quiz was first answered, returning false if it was already answered
+	MarkQuizAnswered(id string) (bool, error)
+	// InsertSharedLesson() stores a saved educational result
+	InsertSharedLesson(lesson SharedLesson) error
+	// GetSharedLesson() returns the shared lesson with the provided id, or ErrLessonNotFound
+	GetSharedLesson(id string) (SharedLesson, error)
+}
+
+// NewLinkStore() returns the LinkStore of the provided storage backend, the memory backend ignores the database
+func NewLinkStore(backend string, db *sql.DB) (LinkStore, error) {
+	switch backend {
+	case configs.BackendPostgres:
+		return NewPostgresStore(db), nil
+	case configs.BackendSQLite:
+		return NewSQLiteStore(db), nil
+	case configs.BackendMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// LocalProvider completes requests through any OpenAI-compatible chat completions endpoint (e.g. Ollama, vLLM, llama.cpp)
+type LocalProvider struct {
+	client openai.Client
+	model  string
+}
+
+// NewLocalProvider() returns a new LocalProvider pointed at the provided base URL (e.g. http://localhost:11434/v1)
+func NewLocalProvider(baseURL string, apiKey string, model string) *LocalProvider {
+	opts := []option.RequestOption{option.WithBaseURL(baseURL)}
+	if apiKey != "" {
+		opts = append(opts, option.WithAPIKey(apiKey))
+	}
+	return &LocalProvider{
+		client: openai.NewClient(opts...),
+		model:  model,
+	}
+}
+
+// Complete() sends the request to the chat completions endpoint
+func (provider *LocalProvider) Complete(ctx context.Context, request Request) (string, error) {
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(request.Messages))
+	for _, message := range request.Messages {
+		switch message.Role {
+		case RoleSystem:
+			messages = append(messages, openai.SystemMessage(message.Content))
+		case RoleAssistant:
+			messages = append(messages, openai.AssistantMessage(message.Content))
+		default:
+			messages = append(messages, openai.UserMessage(message.Content))
+		}
+	}
+	params := openai.ChatCompletionNewParams{
+		Messages: messages,
+		Model:    provider.model,
+	}
+	if request.Schema != nil {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   request.Schema.Name,
+					Schema: request.Schema.Definition,
+					Strict: openai.Bool(true),
+				},
+			},
+		}
+	}
+	completion, err := provider.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", errors.New("local provider returned no choices")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// Model() returns the name of the local model
+func (provider *LocalProvider) Model() string {
+	return provider.model
+}
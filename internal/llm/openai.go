@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// OpenAIProvider completes requests through the OpenAI Responses API
+type OpenAIProvider struct {
+	client openai.Client
+	model  string
+}
+
+// NewOpenAIProvider() returns a new OpenAIProvider authenticated with the provided key
+func NewOpenAIProvider(apiKey string, model string) *OpenAIProvider {
+	return &OpenAIProvider{
+		client: openai.NewClient(option.WithAPIKey(apiKey)),
+		model:  model,
+	}
+}
+
+// Complete() sends the request to the OpenAI Responses API
+func (provider *OpenAIProvider) Complete(ctx context.Context, request Request) (string, error) {
+	input := make(responses.ResponseInputParam, 0, len(request.Messages))
+	for _, message := range request.Messages {
+		input = append(input, responses.ResponseInputItemParamOfMessage(message.Content, responses.EasyInputMessageRole(message.Role)))
+	}
+	params := responses.ResponseNewParams{
+		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: input},
+		Model: provider.model,
+	}
+	if request.Schema != nil {
+		params.Text = responses.ResponseTextConfigParam{
+			Format: responses.ResponseFormatTextConfigUnionParam{
+				OfJSONSchema: &responses.ResponseFormatTextJSONSchemaConfigParam{
+					Name:   request.Schema.Name,
+					Schema: request.Schema.Definition,
+					Strict: openai.Bool(true),
+				},
+			},
+		}
+	}
+	response, err := provider.client.Responses.New(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	return response.OutputText(), nil
+}
+
+// Model() returns the name of the OpenAI model
+func (provider *OpenAIProvider) Model() string {
+	return provider.model
+}
@@ -0,0 +1,67 @@
+// Package llm defines the interface used to query large language models along with its implementations.
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// Role represents the author of a Message
+type Role string
+
+// const here stores all Role enums
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// const here stores all provider names accepted by `NewProvider()`, tests use a `StubProvider` instead
+const (
+	ProviderOpenAI = "openai"
+	ProviderLocal  = "local"
+)
+
+// Message represents a single message in a conversation with a Provider
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Schema represents a JSON schema that constrains the output of a Provider
+type Schema struct {
+	Name       string
+	Definition map[string]any
+}
+
+// Request represents a single completion request sent to a Provider.
+// When Schema is set the provider requests structured output matching it.
+type Request struct {
+	Messages []Message
+	Schema   *Schema
+}
+
+// Provider represents a large language model backend capable of completing a Request
+type Provider interface {
+	// Complete() returns the text output of the model for the provided request
+	Complete(ctx context.Context, request Request) (string, error)
+	// Model() returns the name of the model used to complete requests
+	Model() string
+}
+
+// UserRequest() returns a Request holding a single user message
+func UserRequest(prompt string) Request {
+	return Request{Messages: []Message{{Role: RoleUser, Content: prompt}}}
+}
+
+// NewProvider() returns the Provider matching the provided name
+func NewProvider(name string, model string, baseURL string, apiKey string) (Provider, error) {
+	switch name {
+	case ProviderOpenAI:
+		return NewOpenAIProvider(apiKey, model), nil
+	case ProviderLocal:
+		return NewLocalProvider(baseURL, apiKey, model), nil
+	default:
+		return nil, fmt.Errorf("%s is an invalid LLM provider", name)
+	}
+}
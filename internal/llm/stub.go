@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrStubExhausted is returned when a StubProvider has no scripted responses left
+var ErrStubExhausted = errors.New("stub provider has no scripted responses left")
+
+// StubResponse represents a single scripted reply of a StubProvider
+type StubResponse struct {
+	Text string
+	Err  error
+}
+
+// StubProvider replays scripted responses in order so handlers can be exercised without real API calls.
+// It is only meant for tests, `NewProvider()` never returns it since it has nothing to reply once its script runs out.
+type StubProvider struct {
+	mu        sync.Mutex
+	responses []StubResponse
+	requests  []Request
+}
+
+// NewStubProvider() returns a new StubProvider that replies with the provided responses in order
+func NewStubProvider(responses ...StubResponse) *StubProvider {
+	return &StubProvider{responses: responses}
+}
+
+// Complete() records the request and returns the next scripted response
+func (provider *StubProvider) Complete(ctx context.Context, request Request) (string, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	provider.requests = append(provider.requests, request)
+	if len(provider.responses) == 0 {
+		return "", ErrStubExhausted
+	}
+	response := provider.responses[0]
+	provider.responses = provider.responses[1:]
+	return response.Text, response.Err
+}
+
+// Model() returns the name of the stub model
+func (provider *StubProvider) Model() string {
+	return "stub"
+}
+
+// Requests() returns every request received by the StubProvider so far
+func (provider *StubProvider) Requests() []Request {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	return append([]Request(nil), provider.requests...)
+}
@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStubProvider(t *testing.T) {
+	failure := errors.New("rate limited")
+	provider := NewStubProvider(StubResponse{Text: "first"}, StubResponse{Err: failure}, StubResponse{Text: "third"})
+	tests := []struct {
+		name     string
+		prompt   string
+		wantText string
+		wantErr  error
+	}{
+		{name: "first response", prompt: "one", wantText: "first"},
+		{name: "scripted error", prompt: "two", wantErr: failure},
+		{name: "last response", prompt: "three", wantText: "third"},
+		{name: "script exhausted", prompt: "four", wantErr: ErrStubExhausted},
+	}
+	// the cases share the provider so they run in order
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := provider.Complete(context.Background(), UserRequest(tt.prompt))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Complete() error = %v, want %v", err, tt.wantErr)
+			}
+			if text != tt.wantText {
+				t.Errorf("Complete() = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+	requests := provider.Requests()
+	if len(requests) != len(tests) {
+		t.Fatalf("recorded %d requests, want %d", len(requests), len(tests))
+	}
+	for i, request := range requests {
+		if got := request.Messages[0].Content; got != tests[i].prompt {
+			t.Errorf("request %d prompt = %q, want %q", i, got, tests[i].prompt)
+		}
+	}
+}
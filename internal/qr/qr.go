@@ -0,0 +1,153 @@
+// Package qr renders links as QR codes for facilitators to print on slides and posters.
+// Codes are generated offline as PNG or SVG.
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/JBK2116/phakelinks/types"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// const here stores all QR code format enums
+const (
+	PNG = "png"
+	SVG = "svg"
+)
+
+// const here stores the size limits of a QR code in pixels
+const (
+	minSize     = 64
+	maxSize     = 2048
+	defaultSize = 256
+)
+
+// defaultLevel is used when neither the request nor the configuration picks a valid error-correction level
+const defaultLevel = "M"
+
+// levels maps each error-correction level to the share of the code that can be damaged while it still scans
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+var (
+	ErrInvalidFormat = errors.New("invalid QR code format")
+	ErrInvalidLevel  = errors.New("invalid QR code error-correction level")
+	ErrInvalidSize   = errors.New("invalid QR code size")
+)
+
+// Options represents how a QR code is rendered
+type Options struct {
+	Format string
+	Level  string
+	Size   int
+}
+
+// NewOptions() returns the options of the provided format, level and size, missing values fall back to the configuration.
+// Every invalid value is reported with a field error.
+func NewOptions(format string, level string, size int) (Options, []types.FieldError) {
+	var result types.ValidationResult
+	options := Options{Format: strings.ToLower(format), Level: strings.ToUpper(level), Size: size}
+	if options.Format == "" {
+		options.Format = PNG
+	}
+	if options.Level == "" {
+		options.Level = DefaultLevel()
+	}
+	if options.Size == 0 {
+		options.Size = DefaultSize()
+	}
+	if options.Format != PNG && options.Format != SVG {
+		result.Add("format", "INVALID_QR_FORMAT", fmt.Sprintf("The QR code format must be %s or %s.", PNG, SVG), format)
+	}
+	if _, ok := levels[options.Level]; !ok {
+		result.Add("level", "INVALID_QR_LEVEL", "The QR code error-correction level must be L, M, Q or H.", level)
+	}
+	if options.Size < minSize || options.Size > maxSize {
+		result.Add("size", "INVALID_QR_SIZE", fmt.Sprintf("The QR code size must be between %d and %d pixels.", minSize, maxSize), fmt.Sprint(size))
+	}
+	return options, result.Errors
+}
+
+// DefaultLevel() returns the configured error-correction level, or `defaultLevel` if it is not valid
+func DefaultLevel() string {
+	level := strings.ToUpper(configs.Envs.QRLevel)
+	if _, ok := levels[level]; !ok {
+		return defaultLevel
+	}
+	return level
+}
+
+// DefaultSize() returns the configured size in pixels, or `defaultSize` if it is out of range
+func DefaultSize() int {
+	size := int(configs.Envs.QRSize)
+	if size < minSize || size > maxSize {
+		return defaultSize
+	}
+	return size
+}
+
+// ContentType() returns the media type of the provided format
+func ContentType(format string) string {
+	if format == SVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Encode() returns the QR code of the provided content rendered with the provided options
+func Encode(content string, options Options) ([]byte, error) {
+	level, ok := levels[options.Level]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLevel, options.Level)
+	}
+	if options.Size < minSize || options.Size > maxSize {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSize, options.Size)
+	}
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	switch options.Format {
+	case PNG:
+		return code.PNG(options.Size)
+	case SVG:
+		return renderSVG(code.Bitmap(), options.Size), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, options.Format)
+	}
+}
+
+// DataURI() returns the QR code of the provided content as a data URI that can be used as an image source
+func DataURI(content string, options Options) (string, error) {
+	data, err := Encode(content, options)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", ContentType(options.Format), base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// renderSVG() draws every dark module of the bitmap, including its quiet zone, as a single path scaled to the provided size
+func renderSVG(bitmap [][]bool, size int) []byte {
+	var buffer bytes.Buffer
+	modules := len(bitmap)
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	fmt.Fprintf(&buffer, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buffer, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buffer.WriteString(`"/></svg>`)
+	return buffer.Bytes()
+}
@@ -0,0 +1,33 @@
+package technique
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// TechniqueConn serves the technique catalog.
+type TechniqueConn struct {
+	logger *slog.Logger
+}
+
+// NewTechniqueConn() creates a new TechniqueConn.
+func NewTechniqueConn(logger *slog.Logger) *TechniqueConn {
+	return &TechniqueConn{
+		logger: logger,
+	}
+}
+
+// RegisterRoutes() registers all routes for the TechniqueConn struct
+func (techniqueConn *TechniqueConn) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/techniques", techniqueConn.handleGetTechniques).Methods("GET")
+}
+
+// handleGetTechniques() returns every technique in the catalog
+func (techniqueConn *TechniqueConn) handleGetTechniques(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	json.NewEncoder(writer).Encode(All())
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var (
+	// ErrUnsupportedScheme is returned when a link uses a scheme other than http or https
+	ErrUnsupportedScheme = errors.New("only http and https links are supported")
+	// ErrUserinfo is returned when a link contains credentials before the host
+	ErrUserinfo = errors.New("links must not contain a username or password")
+)
+
+// defaultPorts maps each supported scheme to the port that is implied when none is given
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Canonicalize() returns the canonical form of the provided link or domain.
+// The scheme defaults to https, the host is lowercased and converted to ASCII, and default ports and fragments are removed.
+func Canonicalize(rawLink string) (string, error) {
+	link := strings.TrimSpace(rawLink)
+	if link == "" {
+		return "", fmt.Errorf("%w: link is empty", ErrInvalidURL)
+	}
+	if !strings.Contains(link, "://") {
+		// schemes without an authority such as mailto: or javascript: never contain a dot, unlike a host with a port
+		if parsed, err := url.Parse(link); err == nil && parsed.Scheme != "" && !strings.Contains(parsed.Scheme, ".") {
+			return "", fmt.Errorf("%w: %s", ErrUnsupportedScheme, parsed.Scheme)
+		}
+		link = "https://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidURL, rawLink)
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if _, ok := defaultPorts[parsed.Scheme]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedScheme, parsed.Scheme)
+	}
+	if parsed.User != nil {
+		return "", ErrUserinfo
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("%w: %s has no host", ErrInvalidURL, rawLink)
+	}
+	asciiHost, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidDomain, err)
+	}
+	if port := parsed.Port(); port != "" && port != defaultPorts[parsed.Scheme] {
+		asciiHost = asciiHost + ":" + port
+	}
+	parsed.Host = asciiHost
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String(), nil
+}
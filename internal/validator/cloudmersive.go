@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CloudmersiveValidator validates URLs and domains through the Cloudmersive validation API
+type CloudmersiveValidator struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewCloudmersiveValidator() returns a new CloudmersiveValidator authenticated with the provided key
+func NewCloudmersiveValidator(apiKey string) *CloudmersiveValidator {
+	return &CloudmersiveValidator{
+		apiKey: apiKey,
+		client: &http.Client{},
+	}
+}
+
+// ValidateURL() checks that the provided URL string is a valid, well-formed HTTP/HTTPS URL.
+func (validator *CloudmersiveValidator) ValidateURL(ctx context.Context, rawURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*1)
+	defer cancel()
+	url := "https://api.cloudmersive.com/validate/domain/url/full"
+	method := "POST"
+	body, err := json.Marshal(map[string]string{"URL": rawURL})
+	if err != nil {
+		return err
+	}
+	payload := strings.NewReader(string(body))
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("ApiKey", validator.apiKey)
+	res, err := validator.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	result := make(map[string]any)
+	if err := json.Unmarshal(resBody, &result); err != nil {
+		return err
+	}
+	if valid, ok := result["ValidURL"].(bool); !ok || !valid {
+		return fmt.Errorf("%w: %s", ErrInvalidURL, rawURL)
+	}
+	return nil
+}
+
+// ValidateDomain() checks the the provided domain string is a valid, well-formed web domain
+func (validator *CloudmersiveValidator) ValidateDomain(ctx context.Context, rawDomain string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*1)
+	defer cancel()
+	url := "https://api.cloudmersive.com/validate/domain/check"
+	method := "POST"
+	payload := strings.NewReader(rawDomain)
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("ApiKey", validator.apiKey)
+	res, err := validator.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	result := make(map[string]bool)
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+	if !result["ValidDomain"] {
+		return fmt.Errorf("%w: %s", ErrInvalidDomain, rawDomain)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxDomainLength and maxLabelLength are the limits defined by RFC 1035
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// LocalValidator validates URLs and domains offline using `net/url`, UTS-46 processing and the embedded Public Suffix List
+type LocalValidator struct {
+	profile *idna.Profile
+}
+
+// NewLocalValidator() returns a new LocalValidator
+func NewLocalValidator() *LocalValidator {
+	return &LocalValidator{profile: idna.Lookup}
+}
+
+// ValidateURL() checks that the provided URL string is a valid, well-formed HTTP/HTTPS URL
+func (validator *LocalValidator) ValidateURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidURL, rawURL)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("%w: %s must use http or https", ErrInvalidURL, rawURL)
+	}
+	if parsed.Opaque != "" || parsed.Host == "" {
+		return fmt.Errorf("%w: %s has no host", ErrInvalidURL, rawURL)
+	}
+	if port := parsed.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%w: %s has an invalid port", ErrInvalidURL, rawURL)
+		}
+	}
+	if err := validator.ValidateDomain(ctx, parsed.Hostname()); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidURL, err)
+	}
+	return nil
+}
+
+// ValidateDomain() checks that the provided domain string is a valid, well-formed web domain
+func (validator *LocalValidator) ValidateDomain(ctx context.Context, rawDomain string) error {
+	domain := strings.TrimSuffix(strings.TrimSpace(rawDomain), ".")
+	if domain == "" {
+		return fmt.Errorf("%w: domain is empty", ErrInvalidDomain)
+	}
+	if net.ParseIP(domain) != nil {
+		return fmt.Errorf("%w: %s is an IP address", ErrInvalidDomain, rawDomain)
+	}
+	ascii, err := validator.profile.ToASCII(domain)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDomain, err)
+	}
+	if len(ascii) > maxDomainLength {
+		return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidDomain, rawDomain, maxDomainLength)
+	}
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("%w: %s has no top-level domain", ErrInvalidDomain, rawDomain)
+	}
+	for _, label := range labels {
+		if err := validateLabel(label); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidDomain, err)
+		}
+	}
+	if isNumeric(labels[len(labels)-1]) {
+		return fmt.Errorf("%w: %s has a numeric top-level domain", ErrInvalidDomain, rawDomain)
+	}
+	suffix, icann := publicsuffix.PublicSuffix(ascii)
+	if !icann && !strings.Contains(suffix, ".") {
+		return fmt.Errorf("%w: .%s is not a known public suffix", ErrInvalidDomain, suffix)
+	}
+	if _, err := publicsuffix.EffectiveTLDPlusOne(ascii); err != nil {
+		return fmt.Errorf("%w: %s is a public suffix, not a registrable domain", ErrInvalidDomain, rawDomain)
+	}
+	return nil
+}
+
+// validateLabel() checks a single ASCII label against the letter-digit-hyphen hostname rules
+func validateLabel(label string) error {
+	if label == "" {
+		return fmt.Errorf("empty label")
+	}
+	if len(label) > maxLabelLength {
+		return fmt.Errorf("label %s is longer than %d characters", label, maxLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %s starts or ends with a hyphen", label)
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return fmt.Errorf("label %s contains the invalid character %q", label, c)
+		}
+	}
+	return nil
+}
+
+// isNumeric() checks if the provided string only holds ASCII digits
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,50 @@
+// Package validator checks that user supplied URLs and domains are well-formed before they are used.
+package validator
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrInvalidURL is returned when a URL is malformed or uses an unsupported scheme
+	ErrInvalidURL = errors.New("invalid url")
+	// ErrInvalidDomain is returned when a domain is malformed or has no registrable public suffix
+	ErrInvalidDomain = errors.New("invalid domain")
+)
+
+// Validator represents a backend capable of validating URLs and domains
+type Validator interface {
+	// ValidateURL() checks that the provided URL string is a valid, well-formed HTTP/HTTPS URL
+	ValidateURL(ctx context.Context, rawURL string) error
+	// ValidateDomain() checks that the provided domain string is a valid, well-formed web domain
+	ValidateDomain(ctx context.Context, rawDomain string) error
+}
+
+// ChainValidator runs every validator in order and fails on the first error
+type ChainValidator []Validator
+
+// NewChainValidator() returns a ChainValidator running the provided validators in order
+func NewChainValidator(validators ...Validator) ChainValidator {
+	return ChainValidator(validators)
+}
+
+// ValidateURL() checks the URL against every validator in the chain
+func (chain ChainValidator) ValidateURL(ctx context.Context, rawURL string) error {
+	for _, v := range chain {
+		if err := v.ValidateURL(ctx, rawURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateDomain() checks the domain against every validator in the chain
+func (chain ChainValidator) ValidateDomain(ctx context.Context, rawDomain string) error {
+	for _, v := range chain {
+		if err := v.ValidateDomain(ctx, rawDomain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
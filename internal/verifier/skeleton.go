@@ -0,0 +1,63 @@
+package verifier
+
+import (
+	"strings"
+
+	"github.com/JBK2116/phakelinks/internal/confusable"
+)
+
+// substitutes maps characters that are commonly used to imitate Latin letters but are not UTS #39 confusables,
+// such as digits, back to the letter they imitate
+var substitutes = map[rune]rune{
+	'0': 'o',
+	'1': 'l',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'в': 'b', // CYRILLIC SMALL LETTER VE
+	'κ': 'k', // GREEK SMALL LETTER KAPPA
+}
+
+// sequenceConfusables rewrites multi-character sequences that render like a single letter and are missing from UTS #39
+var sequenceConfusables = strings.NewReplacer("vv", "w")
+
+// asciiConfusablePairs holds ASCII characters that are commonly substituted for one another
+var asciiConfusablePairs = map[[2]byte]struct{}{
+	{'o', '0'}: {},
+	{'l', '1'}: {},
+	{'i', '1'}: {},
+	{'i', 'l'}: {},
+	{'l', 'i'}: {},
+	{'e', '3'}: {},
+	{'a', '4'}: {},
+	{'a', '@'}: {},
+	{'s', '5'}: {},
+	{'s', '$'}: {},
+	{'t', '7'}: {},
+	{'b', '8'}: {},
+	{'g', '9'}: {},
+	{'g', 'q'}: {},
+	{'z', '2'}: {},
+	{'u', 'v'}: {},
+}
+
+// asciiConfusable() checks if the replacement character is a common visual substitute for the original
+func asciiConfusable(original byte, replacement byte) bool {
+	_, ok := asciiConfusablePairs[[2]byte{original, replacement}]
+	return ok
+}
+
+// Skeleton() returns the lowercase UTS #39 skeleton of the provided string after replacing common character substitutes
+func Skeleton(s string) string {
+	var builder strings.Builder
+	for _, c := range strings.ToLower(s) {
+		if sub, ok := substitutes[c]; ok {
+			c = sub
+		}
+		builder.WriteRune(c)
+	}
+	return confusable.Skeleton(sequenceConfusables.Replace(builder.String()))
+}
@@ -0,0 +1,446 @@
+// Package verifier confirms that a generated fake link really uses the phishing technique it claims to use.
+package verifier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/JBK2116/phakelinks/types"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	// ErrInvalidLink is returned when the original or fake link cannot be parsed
+	ErrInvalidLink = errors.New("link could not be parsed")
+	// ErrIdentical is returned when the fake link points to the same place as the original link
+	ErrIdentical = errors.New("fake link is identical to the original link")
+	// ErrBrandMismatch is returned when the fake link no longer resembles the original brand
+	ErrBrandMismatch = errors.New("fake link does not resemble the original brand")
+	// ErrTechniqueMissing is returned when the fake link does not use the requested technique
+	ErrTechniqueMissing = errors.New("fake link does not use the requested technique")
+	// ErrUnknownTechnique is returned when there is no verifier for the requested technique
+	ErrUnknownTechnique = errors.New("unknown phishing technique")
+)
+
+// Link represents a parsed link split into the parts that verifiers inspect
+type Link struct {
+	URL *url.URL
+	// Host is the lowercase Unicode form of the host
+	Host string
+	// ASCIIHost is the lowercase ASCII (punycode) form of the host
+	ASCIIHost string
+	Domain    string
+	Suffix    string
+	Brand     string
+	Sub       string
+}
+
+type verifyFunc func(original Link, fake Link) error
+
+var verifyFuncs = map[types.PhishingTechnique]verifyFunc{
+	types.CharacterSub:     verifyCharacterSubstitution,
+	types.HomoGlyphs:       verifyHomoglyph,
+	types.IDNHomograph:     verifyIDNHomograph,
+	types.DotManipulation:  verifyDotManipulation,
+	types.HyphenInsertion:  verifyHyphenInsertion,
+	types.TLDSwap:          verifyTLDSwap,
+	types.SubDomainAbuse:   verifySubdomainAbuse,
+	types.ComboSquatting:   verifyComboSquatting,
+	types.TypoSquatting:    verifyTypoSquatting,
+	types.Punycode:         verifyPunycode,
+	types.PathManipulation: verifyPathManipulation,
+	types.OpenRedirect:     verifyOpenRedirect,
+	types.AtSymbolAbuse:    verifyAtSymbolAbuse,
+	types.PortAbuse:        verifyPortAbuse,
+	types.HTTPSDeception:   verifyHTTPSDeception,
+	types.LookAlikeDomain:  verifyLookalikeDomain,
+}
+
+// Verify() confirms that the fake link differs from the original, still resembles its brand and uses the provided technique
+func Verify(technique types.PhishingTechnique, originalLink string, fakeLink string) error {
+	fn, ok := verifyFuncs[technique]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTechnique, technique)
+	}
+	original, err := ParseLink(originalLink)
+	if err != nil {
+		return fmt.Errorf("original %w: %s", ErrInvalidLink, err)
+	}
+	fake, err := ParseLink(fakeLink)
+	if err != nil {
+		return fmt.Errorf("fake %w: %s", ErrInvalidLink, err)
+	}
+	if original.ASCIIHost == fake.ASCIIHost && fake.URL.User == nil && original.URL.RequestURI() == fake.URL.RequestURI() {
+		return ErrIdentical
+	}
+	if !resemblesBrand(original, fake) {
+		return fmt.Errorf("%w: expected something resembling '%s'", ErrBrandMismatch, original.Brand)
+	}
+	if err := fn(original, fake); err != nil {
+		return fmt.Errorf("%w (%s): %s", ErrTechniqueMissing, technique, err)
+	}
+	return nil
+}
+
+// ParseLink() parses the provided link or domain into a Link
+func ParseLink(link string) (Link, error) {
+	var parsed Link
+	link = strings.TrimSpace(link)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return parsed, err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return parsed, errors.New("missing host")
+	}
+	if net.ParseIP(host) != nil {
+		return parsed, errors.New("host is an IP address")
+	}
+	asciiHost, err := idna.Punycode.ToASCII(host)
+	if err != nil {
+		return parsed, err
+	}
+	unicodeHost, err := idna.Punycode.ToUnicode(asciiHost)
+	if err != nil {
+		return parsed, err
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(unicodeHost)
+	if err != nil {
+		return parsed, err
+	}
+	suffix, _ := publicsuffix.PublicSuffix(unicodeHost)
+	parsed.URL = u
+	parsed.Host = unicodeHost
+	parsed.ASCIIHost = asciiHost
+	parsed.Domain = domain
+	parsed.Suffix = suffix
+	parsed.Brand = strings.TrimSuffix(domain, "."+suffix)
+	parsed.Sub = strings.TrimSuffix(strings.TrimSuffix(unicodeHost, domain), ".")
+	return parsed, nil
+}
+
+// brandForms() returns the Unicode form of the brand of the link and its punycode form if it differs,
+// an internationalized brand can appear in either form depending on where in the URL it is placed
+func brandForms(link Link) []string {
+	forms := []string{link.Brand}
+	if ascii, err := idna.Punycode.ToASCII(link.Brand); err == nil && ascii != link.Brand {
+		forms = append(forms, ascii)
+	}
+	return forms
+}
+
+// containsBrand() checks if the skeleton of the provided text contains the brand of the original link in any of its forms
+func containsBrand(text string, original Link) bool {
+	skeleton := Skeleton(text)
+	for _, form := range brandForms(original) {
+		if strings.Contains(skeleton, Skeleton(form)) {
+			return true
+		}
+	}
+	return false
+}
+
+// bare() removes the dots and hyphens of the provided skeleton so hyphenated brands compare by their letters
+func bare(skeleton string) string {
+	return strings.NewReplacer(".", "", "-", "").Replace(skeleton)
+}
+
+// resemblesBrand() checks that the original brand can still be recognised somewhere in the fake link
+func resemblesBrand(original Link, fake Link) bool {
+	text := fake.Host + " " + fake.URL.EscapedPath() + " " + fake.URL.RawQuery
+	if fake.URL.User != nil {
+		text = fake.URL.User.String() + " " + text
+	}
+	if unescaped, err := url.QueryUnescape(text); err == nil {
+		text = unescaped
+	}
+	if containsBrand(text, original) {
+		return true
+	}
+	host := bare(Skeleton(fake.Host))
+	labels := strings.FieldsFunc(fake.Host, func(r rune) bool { return r == '.' || r == '-' })
+	for _, form := range brandForms(original) {
+		brand := bare(Skeleton(form))
+		if strings.Contains(host, brand) || Distance(bare(Skeleton(fake.Brand)), brand) <= 2 {
+			return true
+		}
+		for _, label := range labels {
+			if Distance(Skeleton(label), brand) <= 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isASCII() checks if the provided string only holds ASCII characters
+func isASCII(s string) bool {
+	for _, c := range s {
+		if c > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// addsNonASCII() checks if the fake string holds a non-ASCII character that the original string does not,
+// the letters of an internationalized brand are allowed to stay as they are
+func addsNonASCII(original string, fake string) bool {
+	for _, c := range fake {
+		if c > unicode.MaxASCII && !strings.ContainsRune(original, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCharacterSubstitution() checks that a brand letter was swapped for a similar looking ASCII character
+func verifyCharacterSubstitution(original Link, fake Link) error {
+	if addsNonASCII(original.Brand, fake.Brand) {
+		return errors.New("brand contains non-ASCII characters")
+	}
+	if len(fake.Brand) != len(original.Brand) || fake.Brand == original.Brand {
+		return errors.New("brand must keep its length and change at least one character")
+	}
+	for i := 0; i < len(fake.Brand); i++ {
+		if fake.Brand[i] != original.Brand[i] && asciiConfusable(original.Brand[i], fake.Brand[i]) {
+			return nil
+		}
+	}
+	return errors.New("no visually similar character substitution found in the brand")
+}
+
+// verifyHomoglyph() checks that the brand contains a look-alike character from another script
+func verifyHomoglyph(original Link, fake Link) error {
+	if isASCII(fake.Brand) {
+		return errors.New("brand contains no Unicode look-alike characters")
+	}
+	if Skeleton(fake.Brand) != Skeleton(original.Brand) {
+		return errors.New("brand does not render like the original")
+	}
+	return nil
+}
+
+// verifyIDNHomograph() checks that the host is an internationalized domain that renders like the original
+func verifyIDNHomograph(original Link, fake Link) error {
+	found := false
+	for _, c := range fake.Brand {
+		if c > unicode.MaxASCII && !unicode.In(c, unicode.Latin) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("brand contains no non-Latin characters")
+	}
+	if Skeleton(fake.Brand) != Skeleton(original.Brand) {
+		return errors.New("brand does not render like the original")
+	}
+	return nil
+}
+
+// verifyPunycode() checks that the host contains an `xn--` label that decodes to a look-alike of the brand
+func verifyPunycode(original Link, fake Link) error {
+	if !strings.Contains(fake.URL.Hostname(), "xn--") {
+		return errors.New("host contains no xn-- encoded label")
+	}
+	if Skeleton(fake.Brand) != Skeleton(original.Brand) && Distance(Skeleton(fake.Brand), Skeleton(original.Brand)) > 1 {
+		return errors.New("decoded brand does not render like the original")
+	}
+	return nil
+}
+
+// verifyDotManipulation() checks that only the dots of the host were added, moved or removed
+func verifyDotManipulation(original Link, fake Link) error {
+	if fake.Domain == original.Domain {
+		return errors.New("registrable domain is unchanged")
+	}
+	if strings.ReplaceAll(fake.Host, ".", "") != strings.ReplaceAll(original.Host, ".", "") {
+		return errors.New("host differs by more than its dots")
+	}
+	return nil
+}
+
+// verifyHyphenInsertion() checks that a hyphen was inserted into the brand
+func verifyHyphenInsertion(original Link, fake Link) error {
+	if !strings.Contains(fake.Brand, "-") || strings.Count(fake.Brand, "-") <= strings.Count(original.Brand, "-") {
+		return errors.New("brand contains no inserted hyphen")
+	}
+	if strings.ReplaceAll(fake.Brand, "-", "") != strings.ReplaceAll(original.Brand, "-", "") && !strings.Contains(fake.Brand, original.Brand) {
+		return errors.New("hyphenated brand does not match the original")
+	}
+	return nil
+}
+
+// verifyTLDSwap() checks that the brand is unchanged while the public suffix differs
+func verifyTLDSwap(original Link, fake Link) error {
+	if fake.Brand != original.Brand {
+		return errors.New("brand was changed")
+	}
+	if fake.Suffix == original.Suffix {
+		return errors.New("top-level domain is unchanged")
+	}
+	return nil
+}
+
+// verifySubdomainAbuse() checks that the brand appears as a subdomain of a different registrable domain
+func verifySubdomainAbuse(original Link, fake Link) error {
+	if fake.Domain == original.Domain {
+		return errors.New("registrable domain is unchanged")
+	}
+	if !containsBrand(fake.Sub, original) {
+		return errors.New("brand does not appear in a subdomain")
+	}
+	return nil
+}
+
+// verifyComboSquatting() checks that extra words were attached to the brand
+func verifyComboSquatting(original Link, fake Link) error {
+	if fake.Brand == original.Brand {
+		return errors.New("brand is unchanged")
+	}
+	if !strings.Contains(fake.Brand, original.Brand) {
+		return errors.New("brand is not contained in the new domain")
+	}
+	return nil
+}
+
+// verifyTypoSquatting() checks that the brand is within a couple of keystrokes of the original
+func verifyTypoSquatting(original Link, fake Link) error {
+	if addsNonASCII(original.Brand, fake.Brand) {
+		return errors.New("brand contains non-ASCII characters")
+	}
+	if d := Distance(fake.Brand, original.Brand); d < 1 || d > 2 {
+		return fmt.Errorf("brand is %d edits away from the original, expected 1-2", d)
+	}
+	return nil
+}
+
+// verifyPathManipulation() checks that the original host appears in the path of a different domain
+func verifyPathManipulation(original Link, fake Link) error {
+	if fake.Domain == original.Domain {
+		return errors.New("registrable domain is unchanged")
+	}
+	if !strings.Contains(strings.ToLower(fake.URL.Path), original.Domain) {
+		return errors.New("original domain does not appear in the path")
+	}
+	return nil
+}
+
+// verifyOpenRedirect() checks that a query parameter forwards the visitor to a different domain
+func verifyOpenRedirect(original Link, fake Link) error {
+	for _, values := range fake.URL.Query() {
+		for _, value := range values {
+			target, err := ParseLink(value)
+			if err != nil || !strings.Contains(value, ".") {
+				continue
+			}
+			if target.Domain != fake.Domain {
+				return nil
+			}
+		}
+	}
+	return errors.New("no query parameter redirects to a different domain")
+}
+
+// verifyAtSymbolAbuse() checks that the brand sits in the userinfo in front of a different domain
+func verifyAtSymbolAbuse(original Link, fake Link) error {
+	if fake.URL.User == nil {
+		return errors.New("link contains no @ userinfo section")
+	}
+	if !containsBrand(fake.URL.User.Username(), original) {
+		return errors.New("userinfo does not contain the brand")
+	}
+	if fake.Domain == original.Domain {
+		return errors.New("real host is still the original domain")
+	}
+	return nil
+}
+
+// verifyPortAbuse() checks that a port-like number sits next to the brand while the browser connects elsewhere
+func verifyPortAbuse(original Link, fake Link) error {
+	if fake.URL.User != nil {
+		password, ok := fake.URL.User.Password()
+		if ok && isNumeric(password) && containsBrand(fake.URL.User.Username(), original) && fake.Domain != original.Domain {
+			return nil
+		}
+	}
+	port := fake.URL.Port()
+	if port != "" && port != "80" && port != "443" && fake.Domain != original.Domain {
+		return nil
+	}
+	return errors.New("no deceptive port found next to the brand")
+}
+
+// verifyHTTPSDeception() checks that the word https appears in the host
+func verifyHTTPSDeception(original Link, fake Link) error {
+	if !strings.Contains(fake.Host, "https") {
+		return errors.New("host does not contain https")
+	}
+	if fake.Domain == original.Domain && fake.URL.Scheme == original.URL.Scheme {
+		return errors.New("link is still served from the original domain")
+	}
+	return nil
+}
+
+// verifyLookalikeDomain() checks that the brand was replaced by a visually similar registration
+func verifyLookalikeDomain(original Link, fake Link) error {
+	if fake.Brand == original.Brand {
+		return errors.New("brand is unchanged")
+	}
+	if Skeleton(fake.Brand) == Skeleton(original.Brand) {
+		return nil
+	}
+	if Distance(fake.Brand, original.Brand) <= 2 {
+		return nil
+	}
+	return errors.New("brand does not look like the original")
+}
+
+// isNumeric() checks if the provided string only holds ASCII digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Distance() returns the optimal string alignment distance between the two strings
+func Distance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,49 @@
+package verifier
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/JBK2116/phakelinks/types"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		technique types.PhishingTechnique
+		original  string
+		fake      string
+		err       error
+	}{
+		{name: "character substitution", technique: types.CharacterSub, original: "https://paypal.com", fake: "https://paypa1.com"},
+		{name: "homoglyph", technique: types.HomoGlyphs, original: "https://paypal.com", fake: "https://pаypal.com"},
+		{name: "hyphen insertion", technique: types.HyphenInsertion, original: "https://paypal.com", fake: "https://pay-pal.com"},
+		{name: "hyphen insertion into hyphenated brand", technique: types.HyphenInsertion, original: "https://my-bank.com", fake: "https://my-ba-nk.com"},
+		{name: "tld swap", technique: types.TLDSwap, original: "https://paypal.com", fake: "https://paypal.net"},
+		{name: "subdomain abuse", technique: types.SubDomainAbuse, original: "https://paypal.com", fake: "https://paypal.com.account-verify.net"},
+		{name: "combo squatting", technique: types.ComboSquatting, original: "https://paypal.com", fake: "https://paypal-login.com"},
+		{name: "typosquatting", technique: types.TypoSquatting, original: "https://paypal.com", fake: "https://paypl.com"},
+		{name: "at symbol", technique: types.AtSymbolAbuse, original: "https://paypal.com", fake: "https://paypal.com@evil.net"},
+		{name: "https deception", technique: types.HTTPSDeception, original: "https://paypal.com", fake: "https://https-paypal.com"},
+		{name: "lookalike", technique: types.LookAlikeDomain, original: "https://microsoft.com", fake: "https://rnicrosoft.com"},
+		{name: "unicode brand", technique: types.TLDSwap, original: "https://münchen.de", fake: "https://münchen.com"},
+		{name: "punycode brand", technique: types.TLDSwap, original: "https://xn--mnchen-3ya.de", fake: "https://xn--mnchen-3ya.com"},
+		{name: "identical", technique: types.TypoSquatting, original: "https://paypal.com", fake: "https://paypal.com", err: ErrIdentical},
+		{name: "unrelated brand", technique: types.TLDSwap, original: "https://paypal.com", fake: "https://example.net", err: ErrBrandMismatch},
+		{name: "wrong technique", technique: types.HomoGlyphs, original: "https://paypal.com", fake: "https://paypal.net", err: ErrTechniqueMissing},
+		{name: "ascii homoglyph", technique: types.HomoGlyphs, original: "https://paypal.com", fake: "https://paypa1.com", err: ErrTechniqueMissing},
+		{name: "unknown technique", technique: "made-up", original: "https://paypal.com", fake: "https://paypal.net", err: ErrUnknownTechnique},
+		{name: "invalid fake", technique: types.TLDSwap, original: "https://paypal.com", fake: "https://", err: ErrInvalidLink},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.technique, tt.original, tt.fake)
+			if tt.err == nil && err != nil {
+				t.Fatalf("Verify(%s, %q, %q) returned error %v", tt.technique, tt.original, tt.fake, err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Verify(%s, %q, %q) error = %v, want %v", tt.technique, tt.original, tt.fake, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE quizzes (
+    id VARCHAR PRIMARY KEY,
+    format VARCHAR NOT NULL,
+    link VARCHAR NOT NULL,
+    choices JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    answered_at TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE quizzes;
+-- +goose StatementEnd
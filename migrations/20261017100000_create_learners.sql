@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE learners (
+    id VARCHAR PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE learner_techniques (
+    learner_id VARCHAR NOT NULL REFERENCES learners (id) ON DELETE CASCADE,
+    technique VARCHAR NOT NULL,
+    seen INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    correct INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (learner_id, technique)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE learner_techniques;
+DROP TABLE learners;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE clicks (
+    id BIGSERIAL PRIMARY KEY,
+    link_id INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    clicked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ip_hash VARCHAR NOT NULL,
+    ua_class VARCHAR NOT NULL,
+    referrer_host VARCHAR NOT NULL DEFAULT ''
+);
+
+CREATE INDEX clicks_link_id_clicked_at_idx ON clicks (link_id, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE clicks;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE links
+    ADD COLUMN active_from TIMESTAMP,
+    ADD COLUMN expires_at TIMESTAMP,
+    ADD COLUMN max_clicks INTEGER,
+    ADD COLUMN click_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN disabled_at TIMESTAMP,
+    ADD COLUMN swept_at TIMESTAMP;
+
+CREATE INDEX links_expires_at_idx ON links (expires_at) WHERE disabled_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX links_expires_at_idx;
+ALTER TABLE links
+    DROP COLUMN active_from,
+    DROP COLUMN expires_at,
+    DROP COLUMN max_clicks,
+    DROP COLUMN click_count,
+    DROP COLUMN disabled_at,
+    DROP COLUMN swept_at;
+-- +goose StatementEnd
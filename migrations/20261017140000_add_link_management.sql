@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE links ADD COLUMN token_hash VARCHAR;
+
+CREATE TABLE link_versions (
+    id BIGSERIAL PRIMARY KEY,
+    link_id INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    link VARCHAR NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX link_versions_link_id_idx ON link_versions (link_id, id);
+
+-- links created before versioning start their history with their current destination
+INSERT INTO link_versions (link_id, link, changed_at) SELECT id, link, created_at FROM links;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE link_versions;
+ALTER TABLE links DROP COLUMN token_hash;
+-- +goose StatementEnd
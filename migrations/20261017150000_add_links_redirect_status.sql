@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- temporary redirects keep browsers asking the redirect server, so edits, limits and click counts apply to every visit
+ALTER TABLE links ADD COLUMN redirect_status SMALLINT NOT NULL DEFAULT 302;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE links DROP COLUMN redirect_status;
+-- +goose StatementEnd
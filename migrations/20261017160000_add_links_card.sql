@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE links ADD COLUMN card_title VARCHAR;
+ALTER TABLE links ADD COLUMN card_image VARCHAR;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE links DROP COLUMN card_image;
+ALTER TABLE links DROP COLUMN card_title;
+-- +goose StatementEnd
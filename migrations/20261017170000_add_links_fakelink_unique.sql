@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the oldest link keeps a shared slug, later duplicates get their id appended so every row stays reachable
+UPDATE links SET fakelink = fakelink || '-' || id WHERE id NOT IN (SELECT MIN(id) FROM links GROUP BY fakelink);
+ALTER TABLE links ADD CONSTRAINT links_fakelink_key UNIQUE (fakelink);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE links DROP CONSTRAINT links_fakelink_key;
+-- +goose StatementEnd
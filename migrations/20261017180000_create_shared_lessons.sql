@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE shared_lessons (
+    id VARCHAR PRIMARY KEY,
+    link VARCHAR NOT NULL,
+    fake_link VARCHAR NOT NULL,
+    technique VARCHAR NOT NULL,
+    explanation TEXT NOT NULL,
+    model VARCHAR NOT NULL,
+    prompt_version VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE shared_lessons;
+-- +goose StatementEnd
@@ -0,0 +1,69 @@
+// Package migrations embeds the database schema so the binary can migrate its own database.
+// Postgres migrations live in this directory and SQLite migrations in `sqlite/`, a change to the schema adds a file to both with the same version.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/JBK2116/phakelinks/internal/configs"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed *.sql
+var postgresFS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// NewProvider() returns the goose provider of the migrations of the provided storage backend.
+// The provider shares the connection of the application, closing it closes the database.
+// Postgres migrations run under a session advisory lock so replicas starting together never apply the same migration twice.
+func NewProvider(db *sql.DB, backend string) (*goose.Provider, error) {
+	switch backend {
+	case configs.BackendPostgres:
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, err
+		}
+		return goose.NewProvider(goose.DialectPostgres, db, postgresFS, goose.WithSessionLocker(locker))
+	case configs.BackendSQLite, configs.BackendMemory:
+		migrations, err := fs.Sub(sqliteFS, "sqlite")
+		if err != nil {
+			return nil, err
+		}
+		return goose.NewProvider(goose.DialectSQLite3, db, migrations)
+	default:
+		return nil, fmt.Errorf("no migrations for storage backend %q", backend)
+	}
+}
+
+// Up() applies every pending migration of the provided storage backend and returns the resulting schema version
+func Up(ctx context.Context, logger *slog.Logger, db *sql.DB, backend string) (int64, error) {
+	provider, err := NewProvider(db, backend)
+	if err != nil {
+		return 0, err
+	}
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, result := range results {
+		logger.Info("Applied migration", slog.Int64("version", result.Source.Version), slog.String("file", result.Source.Path), slog.Duration("duration", result.Duration))
+	}
+	return provider.GetDBVersion(ctx)
+}
+
+// Versions() returns the schema version of the database and the latest version embedded in the binary
+func Versions(ctx context.Context, db *sql.DB, backend string) (current int64, latest int64, err error) {
+	provider, err := NewProvider(db, backend)
+	if err != nil {
+		return 0, 0, err
+	}
+	return provider.GetVersions(ctx)
+}
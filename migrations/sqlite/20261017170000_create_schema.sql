@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite databases start from the schema Postgres reaches at the same version, IF NOT EXISTS adopts databases created before migrations were embedded
+CREATE TABLE IF NOT EXISTS links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link VARCHAR NOT NULL,
+    fakelink VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    awareness BOOLEAN NOT NULL DEFAULT FALSE,
+    active_from TIMESTAMP,
+    expires_at TIMESTAMP,
+    max_clicks INTEGER,
+    click_count INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP,
+    swept_at TIMESTAMP,
+    token_hash VARCHAR,
+    redirect_status SMALLINT NOT NULL DEFAULT 302,
+    card_title VARCHAR,
+    card_image VARCHAR,
+    CONSTRAINT links_fakelink_key UNIQUE (fakelink)
+);
+
+CREATE INDEX IF NOT EXISTS links_expires_at_idx ON links (expires_at) WHERE disabled_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS link_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_id INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    link VARCHAR NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS link_versions_link_id_idx ON link_versions (link_id, id);
+
+CREATE TABLE IF NOT EXISTS quizzes (
+    id VARCHAR PRIMARY KEY,
+    format VARCHAR NOT NULL,
+    link VARCHAR NOT NULL,
+    choices TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    answered_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS learners (
+    id VARCHAR PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS learner_techniques (
+    learner_id VARCHAR NOT NULL REFERENCES learners (id) ON DELETE CASCADE,
+    technique VARCHAR NOT NULL,
+    seen INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    correct INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (learner_id, technique)
+);
+
+CREATE TABLE IF NOT EXISTS clicks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_id INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    clicked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ip_hash VARCHAR NOT NULL,
+    ua_class VARCHAR NOT NULL,
+    referrer_host VARCHAR NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS clicks_link_id_clicked_at_idx ON clicks (link_id, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS clicks;
+DROP TABLE IF EXISTS learner_techniques;
+DROP TABLE IF EXISTS learners;
+DROP TABLE IF EXISTS quizzes;
+DROP TABLE IF EXISTS link_versions;
+DROP TABLE IF EXISTS links;
+-- +goose StatementEnd
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Mode represents an enum type of the applications state
 type Mode string
 
@@ -7,6 +9,16 @@ type Mode string
 const (
 	Educational Mode = "educational"
 	Prank       Mode = "prank"
+	Quiz        Mode = "quiz"
+)
+
+// QuizFormat represents an enum type of the kind of challenge returned in quiz mode
+type QuizFormat string
+
+// const here stores all QuizFormat enums
+const (
+	PickReal   QuizFormat = "pick-real"
+	RealOrFake QuizFormat = "real-or-fake"
 )
 
 // PhishingTechnique represents an enum type of a PhishingTechnique string
@@ -50,20 +62,105 @@ var AllPhishingTechniques = []PhishingTechnique{
 	LookAlikeDomain,
 }
 
+// Difficulty represents an enum type of how hard a PhishingTechnique is to spot
+type Difficulty string
+
+// const here stores all Difficulty enums
+const (
+	Beginner     Difficulty = "beginner"
+	Intermediate Difficulty = "intermediate"
+	Advanced     Difficulty = "advanced"
+)
+
+// TechniqueDTO represents the catalog entry describing a single PhishingTechnique.
+type TechniqueDTO struct {
+	ID            PhishingTechnique  `json:"id"`
+	Name          string             `json:"name"`
+	Summary       string             `json:"summary"`
+	Description   string             `json:"description"`
+	Difficulty    Difficulty         `json:"difficulty"`
+	DetectionTips []string           `json:"detection_tips"`
+	Examples      []TechniqueExample `json:"examples"`
+	References    []string           `json:"references"`
+	MITRE         []MITRETechnique   `json:"mitre_attack"`
+}
+
+// TechniqueExample represents a legitimate link paired with a fake link built using a technique.
+type TechniqueExample struct {
+	Original string `json:"original"`
+	Fake     string `json:"fake"`
+}
+
+// MITRETechnique represents a MITRE ATT&CK technique that a PhishingTechnique maps to.
+type MITRETechnique struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
 // CreateLink represents the incoming request payload to generate a phishing URL.
 type CreateLinkDTO struct {
-	Link    string   `json:"link"`
-	Mode    string   `json:"mode"`
-	Exclude []string `json:"exclude"`
+	Link string `json:"link"`
+	Mode string `json:"mode"`
+	// Exclude is optional, the learner session already steers the technique choice
+	Exclude []string `json:"exclude,omitempty"`
+	// QuizFormat is only read in quiz mode and defaults to `PickReal`
+	QuizFormat string `json:"quiz_format,omitempty"`
+	// Awareness is only read in prank mode, it shows a lesson page before redirecting to the real destination
+	Awareness bool `json:"awareness,omitempty"`
+	// ActiveFrom, ExpiresAt and MaxClicks are only read in prank mode and limit when and how often the link redirects
+	ActiveFrom *time.Time `json:"active_from,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	MaxClicks  *int       `json:"max_clicks,omitempty"`
+	// RedirectStatus is only read in prank mode and is one of 301, 302, 307 or 308, it defaults to 302
+	RedirectStatus int `json:"redirect_status,omitempty"`
+	// CardTitle and CardImage are only read in prank mode and replace the configured link preview shown by chat apps
+	CardTitle string `json:"card_title,omitempty"`
+	CardImage string `json:"card_image,omitempty"`
+	// Slug is only read in prank mode, it replaces the generated slug and is rejected if it is taken, reserved or profane
+	Slug string `json:"slug,omitempty"`
+	// QR is optional, when set the fake link is also returned as a QR code. Quiz mode has no fake link to encode.
+	QR *QROptionsDTO `json:"qr,omitempty"`
+}
+
+// QROptionsDTO represents how a QR code should be rendered, omitted fields fall back to the configured defaults.
+type QROptionsDTO struct {
+	Format string `json:"format,omitempty"`
+	Level  string `json:"level,omitempty"`
+	Size   int    `json:"size,omitempty"`
+}
+
+// QRCodeDTO represents a rendered QR code of a link.
+type QRCodeDTO struct {
+	Content string `json:"content"`
+	Format  string `json:"format"`
+	Level   string `json:"level"`
+	Size    int    `json:"size"`
+	DataURI string `json:"data_uri"`
 }
 
 // ReturnLink represents the response payload containing the original and generated phishing URL.
+// In quiz mode only the challenge is returned, the links are revealed once it is answered.
+// In prank mode the management token is only ever returned here, the server keeps a hash of it.
 type ReturnLinkDTO struct {
-	Link        string `json:"link"`
-	FakeLink    string `json:"fake_link"`
-	Technique   string `json:"technique,omitempty"`
-	Mode        string `json:"mode"`
-	Explanation string `json:"explanation,omitempty"`
+	Link            string     `json:"link,omitempty"`
+	FakeLink        string     `json:"fake_link,omitempty"`
+	Slug            string     `json:"slug,omitempty"`
+	ManagementToken string     `json:"management_token,omitempty"`
+	Technique       string     `json:"technique,omitempty"`
+	Mode            string     `json:"mode"`
+	Explanation     string     `json:"explanation,omitempty"`
+	LessonID        string     `json:"lesson_id,omitempty"`
+	LessonURL       string     `json:"lesson_url,omitempty"`
+	Quiz            *QuizDTO   `json:"quiz,omitempty"`
+	Awareness       bool       `json:"awareness,omitempty"`
+	ActiveFrom      *time.Time `json:"active_from,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	MaxClicks       *int       `json:"max_clicks,omitempty"`
+	RedirectStatus  int        `json:"redirect_status,omitempty"`
+	CardTitle       string     `json:"card_title,omitempty"`
+	CardImage       string     `json:"card_image,omitempty"`
+	QR              *QRCodeDTO `json:"qr,omitempty"`
 }
 
 // Explanation represents the AI-generated explanation linked to a specific URL mapping.
@@ -73,15 +170,230 @@ type ExplanationDTO struct {
 	Explanation string `json:"explanation"`
 }
 
+// SharedLessonDTO represents a saved educational result that can be shared by its permanent id.
+type SharedLessonDTO struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Link          string    `json:"link"`
+	FakeLink      string    `json:"fake_link"`
+	Technique     string    `json:"technique"`
+	Explanation   string    `json:"explanation"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 type PrankDTO struct {
 	Link string `json:"link"`
 	Slug string `json:"slug,omitempty"`
 }
 
-// ErrorResponse represents an error that occurs during runtime
+// LinkDTO represents the metadata of a prank link returned to its owner.
+type LinkDTO struct {
+	Slug       string     `json:"slug"`
+	FakeLink   string     `json:"fake_link"`
+	Link       string     `json:"link"`
+	Status     string     `json:"status"`
+	Awareness  bool       `json:"awareness"`
+	ActiveFrom *time.Time `json:"active_from,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	MaxClicks  *int       `json:"max_clicks,omitempty"`
+	ClickCount int        `json:"click_count"`
+	// RedirectStatus is the HTTP status code the link redirects with
+	RedirectStatus int              `json:"redirect_status"`
+	CardTitle      *string          `json:"card_title,omitempty"`
+	CardImage      *string          `json:"card_image,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DisabledAt     *time.Time       `json:"disabled_at,omitempty"`
+	Versions       []LinkVersionDTO `json:"versions"`
+}
+
+// LinkVersionDTO represents a single past or present destination of a prank link.
+type LinkVersionDTO struct {
+	Version   int       `json:"version"`
+	Link      string    `json:"link"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// UpdateLinkDTO represents the incoming request payload to change a prank link, omitted fields are left unchanged.
+type UpdateLinkDTO struct {
+	Link           *string    `json:"link,omitempty"`
+	Awareness      *bool      `json:"awareness,omitempty"`
+	ActiveFrom     *time.Time `json:"active_from,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	MaxClicks      *int       `json:"max_clicks,omitempty"`
+	RedirectStatus *int       `json:"redirect_status,omitempty"`
+	// CardTitle and CardImage are removed when set to ""
+	CardTitle *string `json:"card_title,omitempty"`
+	CardImage *string `json:"card_image,omitempty"`
+	// Clear removes the listed limits, each one of "active_from", "expires_at" or "max_clicks"
+	Clear []string `json:"clear,omitempty"`
+}
+
+// QuizDTO represents a quiz challenge without its answer.
+type QuizDTO struct {
+	ID       string     `json:"id"`
+	Format   QuizFormat `json:"format"`
+	Question string     `json:"question"`
+	Choices  []string   `json:"choices"`
+}
+
+// QuizAnswerDTO represents the incoming request payload to answer a quiz.
+// For `PickReal` the answer is one of the choices, for `RealOrFake` it is either "real" or "fake".
+type QuizAnswerDTO struct {
+	Answer string `json:"answer"`
+}
+
+// QuizResultDTO represents the graded answer of a quiz along with the lesson for every fake choice.
+type QuizResultDTO struct {
+	ID            string          `json:"id"`
+	Correct       bool            `json:"correct"`
+	Answer        string          `json:"answer"`
+	CorrectAnswer string          `json:"correct_answer"`
+	Link          string          `json:"link"`
+	Choices       []QuizChoiceDTO `json:"choices"`
+}
+
+// QuizChoiceDTO represents a single revealed choice of a quiz.
+type QuizChoiceDTO struct {
+	Link        string `json:"link"`
+	Real        bool   `json:"real"`
+	Technique   string `json:"technique,omitempty"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// MasteryDTO represents how a learner has done on a single PhishingTechnique.
+type MasteryDTO struct {
+	Technique PhishingTechnique `json:"technique"`
+	Seen      int               `json:"seen"`
+	Attempts  int               `json:"attempts"`
+	Correct   int               `json:"correct"`
+	Mastery   float64           `json:"mastery"`
+}
+
+// ProgressDTO represents the response payload listing the mastery of every technique for a learner session.
+type ProgressDTO struct {
+	Techniques []MasteryDTO `json:"techniques"`
+}
+
+// LinkStatsDTO represents the response payload holding the click analytics of a prank link.
+type LinkStatsDTO struct {
+	Slug       string           `json:"slug"`
+	Total      int              `json:"total"`
+	Unique     int              `json:"unique"`
+	Interval   string           `json:"interval"`
+	Series     []ClickBucketDTO `json:"series"`
+	UserAgents map[string]int   `json:"user_agents"`
+	Referrers  map[string]int   `json:"referrers"`
+}
+
+// ClickBucketDTO represents the clicks of a prank link within a single interval of the time series.
+type ClickBucketDTO struct {
+	Start  time.Time `json:"start"`
+	Total  int       `json:"total"`
+	Unique int       `json:"unique"`
+}
+
+// AnalyzeDTO represents the incoming request payload to analyze a suspicious URL.
+type AnalyzeDTO struct {
+	URL   string `json:"url"`
+	Brand string `json:"brand,omitempty"`
+}
+
+// AnalysisDTO represents the response payload listing every technique detected in a suspicious URL.
+type AnalysisDTO struct {
+	URL               string       `json:"url"`
+	Host              string       `json:"host"`
+	ASCIIHost         string       `json:"ascii_host"`
+	RegistrableDomain string       `json:"registrable_domain"`
+	DisplayedBrand    string       `json:"displayed_brand,omitempty"`
+	ClaimedBrand      string       `json:"claimed_brand,omitempty"`
+	Findings          []FindingDTO `json:"findings"`
+}
+
+// FindingDTO represents a single technique detected in a suspicious URL along with the lesson for it.
+type FindingDTO struct {
+	Technique     PhishingTechnique `json:"technique"`
+	Name          string            `json:"name"`
+	Confidence    float64           `json:"confidence"`
+	Evidence      []EvidenceDTO     `json:"evidence"`
+	Description   string            `json:"description"`
+	DetectionTips []string          `json:"detection_tips"`
+}
+
+// EvidenceDTO represents a single observation that supports a FindingDTO.
+type EvidenceDTO struct {
+	Kind      string   `json:"kind"`
+	Detail    string   `json:"detail"`
+	Value     string   `json:"value,omitempty"`
+	Positions []int    `json:"positions,omitempty"`
+	Scripts   []string `json:"scripts,omitempty"`
+	// Restriction is the UTS #39 restriction level of the value, only set for script evidence
+	Restriction string `json:"restriction,omitempty"`
+}
+
+// PermutationDTO represents a single lookalike of a monitored domain and the technique that produced it.
+type PermutationDTO struct {
+	Domain    string            `json:"domain"`
+	ASCII     string            `json:"ascii"`
+	Technique PhishingTechnique `json:"technique"`
+	Detail    string            `json:"detail"`
+}
+
+// PermutationPageDTO represents a single page of the lookalikes of a monitored domain.
+type PermutationPageDTO struct {
+	Domain       string           `json:"domain"`
+	Total        int              `json:"total"`
+	Offset       int              `json:"offset"`
+	Limit        int              `json:"limit"`
+	NextOffset   *int             `json:"next_offset,omitempty"`
+	Permutations []PermutationDTO `json:"permutations"`
+}
+
+// ErrorResponse represents an error that occurs during runtime.
+// Validation failures also list every invalid field in Errors, the top level fields always describe the first one.
 type ErrorResponse struct {
-	Error   string `json:"error"`
+	Error   string       `json:"error"`
+	Message string       `json:"message"`
+	Value   string       `json:"value,omitempty"`
+	Extra   string       `json:"extra,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError represents a single invalid field of a request payload
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 	Value   string `json:"value,omitempty"`
-	Extra   string `json:"extra,omitempty"`
+}
+
+// ValidationResult collects every FieldError found while validating a request payload
+type ValidationResult struct {
+	Errors []FieldError
+}
+
+// Add() records a new FieldError in the ValidationResult
+func (result *ValidationResult) Add(field string, code string, message string, value string) {
+	result.Errors = append(result.Errors, FieldError{Field: field, Code: code, Message: message, Value: value})
+}
+
+// Valid() checks if the ValidationResult holds no errors
+func (result *ValidationResult) Valid() bool {
+	return len(result.Errors) == 0
+}
+
+// ErrorResponse() returns the ValidationResult as an ErrorResponse, or nil if there are no errors
+func (result *ValidationResult) ErrorResponse() *ErrorResponse {
+	if result.Valid() {
+		return nil
+	}
+	first := result.Errors[0]
+	return &ErrorResponse{
+		Error:   first.Code,
+		Message: first.Message,
+		Value:   first.Value,
+		Errors:  result.Errors,
+	}
 }